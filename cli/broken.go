@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/TofuOverdose/WebMapMaker/internal/report"
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+)
+
+// runBroken implements the "broken" command, which crawls the website and reports the links that fail to load along with the pages linking to them.
+// It returns the exit code: 0 if no broken links were found, 1 if there are some and 2 if the crawl couldn't be done
+func runBroken(args []string) int {
+	fs := flag.NewFlagSet("broken", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: makemap broken -t <URL> [options]")
+		fs.PrintDefaults()
+	}
+	pTargetURL := fs.String("t", "", "Target URL to start crawling from")
+	pOutputPath := fs.String("o", "", "Path to the file to write the report into (by default, the report is printed to stdout)")
+	pMaxRoutines := fs.Int("mr", 0, "Number of pages crawled at the same time (16 by default)")
+	pDebugFailures := fs.String("debug-failures", "", "Directory to write every failed HTTP exchange into as .http file, including the beginning of response body")
+	pSearchOpts := fs.String("sp", "", "Search rules for crawler separated by commas. Available options: ignoreTopLevelDomain, includeWithQuery, includeSubdomains")
+	fs.Parse(args)
+
+	if err := validateURL(*pTargetURL); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+	options, err := parseSearchOptions(*pSearchOpts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+	if *pMaxRoutines > 0 {
+		options = append(options, linkcrawler.OptionMaxRoutines(uint(*pMaxRoutines)))
+	}
+	if *pDebugFailures != "" {
+		options = append(options, linkcrawler.OptionDebugDir(*pDebugFailures))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopSigs := make(chan os.Signal, 1)
+	signal.Notify(stopSigs, syscall.SIGINT, syscall.SIGTERM)
+
+	resChan, err := linkcrawler.Crawl(ctx, *pTargetURL, options...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+
+	checker := report.NewBrokenLinkChecker()
+	pages := 0
+	for done := false; !done; {
+		select {
+		case <-stopSigs:
+			fmt.Fprintln(os.Stderr, "Aborted")
+			return 2
+		case res, ok := <-resChan:
+			if !ok {
+				done = true
+				break
+			}
+			pages++
+			checker.Add(res)
+		}
+	}
+
+	broken := checker.BrokenLinks()
+	out := os.Stdout
+	if *pOutputPath != "" {
+		if out, err = os.Create(*pOutputPath); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 2
+		}
+		defer out.Close()
+	}
+	if err := report.WriteBrokenLinkReport(out, broken); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+	fmt.Fprintf(os.Stderr, "Found %d broken links while crawling %d pages\n", len(broken), pages)
+	if len(broken) > 0 {
+		counts := make(map[linkcrawler.ErrorCategory]int)
+		for _, bl := range broken {
+			counts[bl.Category]++
+		}
+		fmt.Fprintf(os.Stderr, "Broken links by category: %s\n", formatCategories(counts))
+	}
+	if len(broken) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -1,32 +1,85 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/TofuOverdose/WebMapMaker/internal/linkcrawler"
-	"github.com/TofuOverdose/WebMapMaker/internal/sitemap"
+	"github.com/TofuOverdose/WebMapMaker/internal/export"
+	"github.com/TofuOverdose/WebMapMaker/internal/graph"
+	"github.com/TofuOverdose/WebMapMaker/internal/linkcheck"
+	"github.com/TofuOverdose/WebMapMaker/internal/report"
+	"github.com/TofuOverdose/WebMapMaker/internal/sitetree"
 	"github.com/TofuOverdose/WebMapMaker/internal/utils/gost"
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/sitemap"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
+// stdoutPath is the output path telling to write the output to stdout
+const stdoutPath = "-"
+
+// exportTypes are the output types written with export package instead of sitemap
+var exportTypes = []string{"JSON", "NDJSON", "CSV"}
+
+// treeTypes are the output types rendering crawled pages as site tree with sitetree package
+var treeTypes = []string{"HTML", "MD", "TREE"}
+
 type InputData struct {
-	TargetURL  string
-	OutputPath string
-	OutputType string
-	Options    []linkcrawler.Option
-	LogWriter  io.WriteCloser
+	TargetURL   string
+	OutputPath  string
+	OutputType  string
+	BaseURL     string
+	Gzip        bool
+	Lenient     bool
+	News        bool
+	NewsInclude []string
+	NewsName    string
+	Hreflang    bool
+	// HreflangReportPath is where the report on hreflang problems is written, it's empty if the report is not requested
+	HreflangReportPath string
+	// GraphPath is where the link graph is written, it's empty if the graph is not requested
+	GraphPath   string
+	GraphFormat graph.Format
+	// ExternalReportPath is where the report on dead and redirected outbound links is written, it's empty if the links are not checked
+	ExternalReportPath string
+	ExternalOptions    []linkcheck.Option
+	// AssetReportPath is where the report on missing and slow assets is written, it's empty if the assets are not checked
+	AssetReportPath string
+	AssetSlow       time.Duration
+	AssetOptions    []linkcheck.Option
+	// FragmentReportPath is where the report on links to missing anchors is written, it's empty if the report is not requested
+	FragmentReportPath string
+	// TreeOptions configure rendering of the site tree for html, markdown and tree formats
+	TreeOptions []sitetree.RenderOption
+	// KeepPartial makes the output be written without asking when the crawl is interrupted
+	KeepPartial bool
+	Options     []linkcrawler.Option
+	LogWriter   io.WriteCloser
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "broken" {
+		os.Exit(runBroken(os.Args[2:]))
+	}
+
 	inputData, err := getInputData()
 	if err != nil {
 		log.Fatal(err)
@@ -34,7 +87,66 @@ func main() {
 
 	defer inputData.LogWriter.Close()
 
-	results := make([]linkcrawler.SearchResult, 0)
+	var sitemapWriter *sitemap.Writer
+	var exporter export.ResultWriter
+	var siteTree *sitetree.Tree
+	// The outputs are written under temporary names and only take their places when they are complete
+	var output pendingOutput
+	pending := make([]pendingOutput, 0)
+	defer func() {
+		discardOutputs(pending)
+	}()
+	if isExportType(inputData.OutputType) {
+		exporter, output, err = openExporter(inputData)
+	} else if isTreeType(inputData.OutputType) {
+		// The tree can only be rendered once all pages are known, so the output file is created at the end
+		siteTree = sitetree.NewTree()
+	} else {
+		sitemapWriter, output, err = openSitemapWriter(inputData)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if output != nil {
+		pending = append(pending, output)
+	}
+
+	// The crawl and the checks of links stop together when it's cancelled
+	jobCtx, jobCancel := context.WithCancel(context.Background())
+
+	var hreflangChecker *report.HreflangChecker
+	if inputData.HreflangReportPath != "" {
+		hreflangChecker = report.NewHreflangChecker()
+	}
+
+	var linkGraph *graph.Graph
+	if inputData.GraphPath != "" {
+		linkGraph = graph.NewGraph()
+	}
+
+	var externalChecker *report.ExternalLinkChecker
+	if inputData.ExternalReportPath != "" {
+		externalChecker = report.NewExternalLinkChecker(jobCtx, inputData.ExternalOptions...)
+	}
+
+	var assetChecker *report.AssetChecker
+	if inputData.AssetReportPath != "" {
+		assetChecker = report.NewAssetChecker(jobCtx, inputData.AssetSlow, inputData.AssetOptions...)
+	}
+
+	var fragmentChecker *report.FragmentChecker
+	if inputData.FragmentReportPath != "" {
+		fragmentChecker = report.NewFragmentChecker()
+	}
+
+	var news *newsSitemap
+	if inputData.News {
+		if news, err = openNewsSitemap(inputData); err != nil {
+			discardOutputs(pending)
+			log.Fatal(err)
+		}
+		pending = append(pending, news.file)
+	}
 
 	// Configuring CLI
 	type linksDisplayStats struct {
@@ -48,6 +160,11 @@ func main() {
 		AcceptedCount:   0,
 		FailedCount:     0,
 	}
+	failures := make(map[linkcrawler.ErrorCategory]int)
+	// debugFailed tells if writing into debug directory failed, it's only reported once since the rest of writes likely fail the same way
+	debugFailed := false
+	// leftOut is the number of pages that didn't fit into the sitemap once it reached its limits
+	leftOut := 0
 	sdt := "\t[ {{.AcceptedCount}} accepted | {{.FailedCount}} errors | {{.TotalFoundCount}} total links found ]"
 
 	statsDisplay, err := gost.NewDisplay(sdt, linkStats)
@@ -72,13 +189,17 @@ func main() {
 	)
 
 	statusBar := gost.NewStatusBar(tr, pb, statsDisplay, timer)
+	if inputData.OutputPath == stdoutPath {
+		// Keep stdout clean for the piped output
+		statusBar.SetOutput(os.Stderr)
+	}
 
-	jobCtx, jobCancel := context.WithCancel(context.Background())
-	stopSigs := make(chan os.Signal)
+	stopSigs := make(chan os.Signal, 1)
 	signal.Notify(stopSigs, syscall.SIGINT, syscall.SIGTERM)
 
 	resChan, err := linkcrawler.Crawl(jobCtx, inputData.TargetURL, inputData.Options...)
 	if err != nil {
+		discardOutputs(pending)
 		log.Fatal(err)
 	}
 
@@ -86,68 +207,434 @@ func main() {
 
 	statusBar.Print("Started crawling the website")
 
+	aborted := false
 	for {
 		select {
 		case <-stopSigs:
+			if aborted {
+				// The second signal quits without writing anything
+				statusBar.Close()
+				statusBar.Print("Aborted")
+				return
+			}
+			aborted = true
 			jobCancel()
-			statusBar.Close()
-			statusBar.Print("Aborted")
-			return
+			statusBar.Print("Stopping the crawl, press Ctrl+C again to quit right away...")
 		case res, ok := <-resChan:
 			if ok {
+				if hreflangChecker != nil {
+					hreflangChecker.Add(res)
+				}
+				if linkGraph != nil {
+					linkGraph.Add(res)
+				}
+				if externalChecker != nil {
+					externalChecker.Add(res)
+				}
+				if assetChecker != nil {
+					assetChecker.Add(res)
+				}
+				if fragmentChecker != nil {
+					fragmentChecker.Add(res)
+				}
+				if exporter != nil {
+					if err := exporter.Write(res); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+				}
 				linkStats.TotalFoundCount++
+				if res.DebugError != nil && !debugFailed {
+					debugFailed = true
+					statusBar.Printf("Failed to write failure of %s into debug directory, some failures may be missing there: %s", res.Addr, res.DebugError.Error())
+				}
 				if res.Error != nil {
 					linkStats.FailedCount++
-					statusBar.Printf("%s: %s", res.Addr, res.Error.Error())
-				} else {
+					failures[res.Category]++
+					statusBar.Printf("%s: [%s] %s", res.Addr, res.Category, res.Error.Error())
+				} else if siteTree != nil {
+					linkStats.AcceptedCount++
+					if err := siteTree.Add(res.Addr, res.Meta.Title); err != nil {
+						statusBar.Printf("%s: %s", res.Addr, err.Error())
+					}
+				} else if sitemapWriter != nil {
 					linkStats.AcceptedCount++
-					results = append(results, res)
+					// Entries are written as soon as they are found so the memory consumption stays flat
+					u, violations := makeSitemapUrl(res)
+					for _, v := range violations {
+						statusBar.Printf("Skipped part of sitemap entry %s", v)
+					}
+					if news != nil {
+						_, err := news.Add(res)
+						if ve, ok := err.(*sitemap.ValidationError); ok {
+							statusBar.Printf("Skipped news article %s: %s", res.Addr, ve.Error())
+						} else if err == sitemap.ErrLimitExceeded {
+							statusBar.Printf("Skipped news article %s: news sitemap is limited to %d articles", res.Addr, sitemap.MaxNewsUrls)
+						} else if err != nil {
+							msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+							inputData.LogWriter.Write([]byte(msg))
+							return
+						}
+					}
+					if leftOut > 0 {
+						leftOut++
+					} else if err := sitemapWriter.Add(u); err != nil {
+						if ve, ok := err.(*sitemap.ValidationError); ok {
+							statusBar.Printf("Skipped sitemap entry %s: %s", res.Addr, ve.Error())
+						} else if err == sitemap.ErrLimitExceeded {
+							// The crawl goes on for the reports, only the sitemap takes no more entries
+							leftOut++
+							if inputData.OutputType == "DIR" {
+								statusBar.Printf("Sitemap reached the limit of %d files, the rest of pages are left out", sitemap.MaxSitemaps)
+							} else {
+								statusBar.Printf("Sitemap reached the limit of %d URLs, the rest of pages are left out. Use directory output to split it into several files", sitemapWriter.Count())
+							}
+						} else {
+							msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+							inputData.LogWriter.Write([]byte(msg))
+							return
+						}
+					}
 				}
 
 				// Update display data
 				statsDisplay.SetData(linkStats)
 			} else {
 				//statusBar.Close()
-				statusBar.Print("Finished crawling. Building sitemap...")
-				us := sitemap.NewUrlSet()
-
-				for _, res := range results {
-					us.AddUrl(*sitemap.NewUrl(res.Addr, "", "", 0.0))
-				}
-				// Open output file
-				f, err := os.Create(inputData.OutputPath)
-				if err != nil {
-					log.Fatal(err)
+				if aborted && !confirmPartial(inputData, statusBar, tr, linkStats.AcceptedCount, stopSigs) {
+					statusBar.Print("Aborted")
 					return
 				}
-				defer f.Close()
-
-				switch inputData.OutputType {
-				case "XML":
-					err = us.WriteXml(f)
-				case "TXT":
-					err = us.WritePlain(f)
+				if len(failures) > 0 {
+					statusBar.Printf("%d failures by category: %s", linkStats.FailedCount, formatCategories(failures))
 				}
-				if err != nil {
-					msg := fmt.Sprintf("FATAL: %s\n", err.Error())
-					inputData.LogWriter.Write([]byte(msg))
-					return
+				outputName := inputData.OutputPath
+				if outputName == stdoutPath {
+					outputName = "stdout"
+				}
+				if siteTree != nil {
+					if err := writeTree(siteTree, inputData); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("Site tree with %d pages saved to %s", linkStats.AcceptedCount, outputName)
+				} else if exporter != nil {
+					if err := exporter.Close(); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					if err := output.Commit(); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("%d crawl results saved to %s as %s", linkStats.TotalFoundCount, outputName, inputData.OutputType)
+				} else {
+					statusBar.Print("Finished crawling. Finishing sitemap...")
+					if err := sitemapWriter.Close(); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					if err := output.Commit(); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					if index := sitemapWriter.Index(); index != nil {
+						statusBar.Printf("Sitemap with %d URLs split into %d files and saved to %s", sitemapWriter.Count(), len(index.Sitemaps), outputName)
+					} else {
+						statusBar.Printf("Sitemap with %d URLs saved to %s", sitemapWriter.Count(), outputName)
+					}
+					if leftOut > 0 {
+						statusBar.Printf("%d pages didn't fit into the sitemap", leftOut)
+					}
+				}
+				if news != nil {
+					if err := news.Close(); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("News sitemap with %d articles saved to %s", news.Count(), news.Path)
+				}
+				if hreflangChecker != nil {
+					issues := hreflangChecker.Issues()
+					if err := writeReport(inputData.HreflangReportPath, func(w io.Writer) error {
+						return report.WriteHreflangReport(w, issues)
+					}); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("Found %d hreflang issues, the report is saved to %s", len(issues), inputData.HreflangReportPath)
+				}
+				if linkGraph != nil {
+					if err := writeReport(inputData.GraphPath, func(w io.Writer) error {
+						return linkGraph.Write(w, inputData.GraphFormat)
+					}); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("Link graph with %d links saved to %s", len(linkGraph.Edges), inputData.GraphPath)
+				}
+				if fragmentChecker != nil {
+					issues := fragmentChecker.Issues()
+					if err := writeReport(inputData.FragmentReportPath, func(w io.Writer) error {
+						return report.WriteFragmentReport(w, issues)
+					}); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("Found %d links to missing anchors, the report is saved to %s", len(issues), inputData.FragmentReportPath)
+				}
+				if externalChecker != nil {
+					statusBar.Print("Waiting for the outbound links to be checked...")
+					issues, checked := externalChecker.Issues()
+					if err := writeReport(inputData.ExternalReportPath, func(w io.Writer) error {
+						return report.WriteExternalLinkReport(w, issues)
+					}); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("Checked %d outbound links, found %d dead or redirected, the report is saved to %s", checked, len(issues), inputData.ExternalReportPath)
+				}
+				if assetChecker != nil {
+					statusBar.Print("Waiting for the assets to be checked...")
+					issues, checked := assetChecker.Issues()
+					if err := writeReport(inputData.AssetReportPath, func(w io.Writer) error {
+						return report.WriteAssetReport(w, issues)
+					}); err != nil {
+						msg := fmt.Sprintf("FATAL: %s\n", err.Error())
+						inputData.LogWriter.Write([]byte(msg))
+						return
+					}
+					statusBar.Printf("Checked %d assets, found %d missing or slow, the report is saved to %s", checked, len(issues), inputData.AssetReportPath)
 				}
-				statusBar.Printf("Sitemap saved to %s", inputData.OutputPath)
 				return
 			}
 		}
 	}
 }
 
+// formatCategories lists the numbers of failures of each category, most frequent first
+func formatCategories(counts map[linkcrawler.ErrorCategory]int) string {
+	categories := make([]linkcrawler.ErrorCategory, 0, len(counts))
+	for c := range counts {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if counts[categories[i]] != counts[categories[j]] {
+			return counts[categories[i]] > counts[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	parts := make([]string, len(categories))
+	for i, c := range categories {
+		parts[i] = fmt.Sprintf("%s: %d", c, counts[c])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// makeSitemapUrl converts the crawled page into sitemap entry.
+// Videos lacking the fields required by the protocol and invalid alternates are left out and reported by the returned violations
+func makeSitemapUrl(res linkcrawler.SearchResult) (sitemap.Url, []sitemap.Violation) {
+	lastmod := ""
+	if t, err := http.ParseTime(res.Headers.Get("Last-Modified")); err == nil {
+		lastmod = sitemap.FormatTime(t)
+	}
+	u := sitemap.NewUrl(res.Addr, lastmod, "", 0.0)
+	for _, img := range res.Images {
+		u.Images = append(u.Images, *sitemap.NewImage(img.URL.String(), img.Title, img.Caption))
+	}
+	violations := make([]sitemap.Violation, 0)
+	for _, a := range res.Alternates {
+		alternate := sitemap.NewAlternate(a.Lang, a.URL.String())
+		if av := sitemap.ValidateAlternate(res.Addr, *alternate); len(av) > 0 {
+			violations = append(violations, av...)
+			continue
+		}
+		u.Alternates = append(u.Alternates, *alternate)
+	}
+	for _, v := range res.Videos {
+		video := sitemap.NewVideo(v.ThumbnailURL.String(), v.Title, v.Description, v.ContentURL.String(), v.PlayerURL.String(), v.Duration)
+		if vv := sitemap.ValidateVideo(res.Addr, *video); len(vv) > 0 {
+			violations = append(violations, vv...)
+			continue
+		}
+		u.Videos = append(u.Videos, *video)
+	}
+	return *u, violations
+}
+
+// writeReport creates the file at path and writes report into it with write
+func writeReport(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// isExportType checks if the output type is one of machine-readable crawl result formats rather than sitemap
+func isExportType(outputType string) bool {
+	return hasType(exportTypes, outputType)
+}
+
+// isTreeType checks if the output type is one of site tree formats rather than sitemap
+func isTreeType(outputType string) bool {
+	return hasType(treeTypes, outputType)
+}
+
+func hasType(types []string, outputType string) bool {
+	for _, t := range types {
+		if outputType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTree renders the site tree into the output in the format requested by user
+func writeTree(tree *sitetree.Tree, inputData *InputData) error {
+	f, err := createOutputFile(inputData.OutputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Discard()
+	switch inputData.OutputType {
+	case "HTML":
+		err = tree.WriteHTML(f, inputData.TreeOptions...)
+	case "MD":
+		err = tree.WriteMarkdown(f, inputData.TreeOptions...)
+	default:
+		err = tree.WriteASCII(f, inputData.TreeOptions...)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Commit()
+}
+
+// discardOutputs removes the outputs that are not committed
+func discardOutputs(outputs []pendingOutput) {
+	for _, o := range outputs {
+		o.Discard()
+	}
+}
+
+// createOutputFile creates the output file under a temporary name, or returns Stdout if the output is requested there
+func createOutputFile(path string) (*pendingFile, error) {
+	if path == stdoutPath {
+		return &pendingFile{File: os.Stdout}, nil
+	}
+	return createPendingFile(path)
+}
+
+// openExporter prepares the writer of crawl results in the format requested by user.
+// The returned output must be committed after closing the writer
+func openExporter(inputData *InputData) (export.ResultWriter, pendingOutput, error) {
+	format, err := export.ParseFormat(inputData.OutputType)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := createOutputFile(inputData.OutputPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err := export.NewResultWriter(f, format)
+	if err != nil {
+		f.Discard()
+		return nil, nil, err
+	}
+	return w, f, nil
+}
+
+// openSitemapWriter prepares the writer for the output requested by user.
+// The returned output must be committed after closing the writer
+func openSitemapWriter(inputData *InputData) (*sitemap.Writer, pendingOutput, error) {
+	writeOptions := make([]sitemap.WriteOption, 0)
+	if inputData.Lenient {
+		writeOptions = append(writeOptions, sitemap.WriteOptionLenient())
+	}
+
+	if inputData.OutputType == "DIR" {
+		writeOptions = append(writeOptions, sitemap.WriteOptionBaseURL(inputData.BaseURL))
+		if inputData.Gzip {
+			writeOptions = append(writeOptions, sitemap.WriteOptionGzip())
+		}
+		dir, err := createPendingDir(inputData.OutputPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		w, err := sitemap.NewSplitWriter(dir.Path, writeOptions...)
+		if err != nil {
+			dir.Discard()
+			return nil, nil, err
+		}
+		return w, dir, nil
+	}
+
+	if strings.HasSuffix(inputData.OutputType, ".GZ") {
+		writeOptions = append(writeOptions, sitemap.WriteOptionGzip())
+	}
+	if strings.HasPrefix(inputData.OutputType, "TXT") {
+		writeOptions = append(writeOptions, sitemap.WriteOptionPlain())
+	}
+	f, err := createOutputFile(inputData.OutputPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sitemap.NewWriter(f, writeOptions...), f, nil
+}
+
 func getInputData() (*InputData, error) {
 	inputData := InputData{}
 
 	// First define the flags
 	pTargetURL := flag.String("t", "", "Target URL to start crawling from")
-	pOutputPath := flag.String("o", "", "Output file (either TXT or XML)")
+	pOutputPath := flag.String("o", "", "Output file (XML, TXT, JSON, NDJSON or CSV, sitemaps optionally gzipped as .xml.gz or .txt.gz), directory to write sitemap split into several files, or - to write to stdout")
+	pFormat := flag.String("format", "", "Output format: xml, txt, json, ndjson, csv, html, markdown or tree (by default, it's taken from the output file extension)")
+	pTreeDepth := flag.Int("tree-depth", 0, "Collapse the site tree below this depth, used with html, markdown and tree formats (0 renders the whole tree)")
+	pTreeSort := flag.String("tree-sort", string(sitetree.SortPath), "Order of site tree nodes: path, title or size (pages with most subpages first)")
+	pBaseURL := flag.String("base", "", "Base URL the sitemap files are served from, used when the output is a directory (defaults to target URL)")
+	pGzip := flag.Bool("gz", false, "Compress split sitemap files with gzip, used when the output is a directory")
+	pKeepPartial := flag.Bool("keep-partial", false, "Write the output with the pages found so far without asking when the crawl is interrupted with Ctrl+C")
+	pLenient := flag.Bool("lenient", false, "Write the sitemap even if some of its entries violate the sitemap protocol")
 	pLogFile := flag.String("log", "", "Path to log file")
-	pMaxRoutines := flag.Int("mr", 0, "Set positive number to limit the number of spawned goroutines")
+	pMaxRoutines := flag.Int("mr", 0, "Number of pages crawled at the same time (16 by default)")
+	pDeterministic := flag.Bool("deterministic", false, "Crawl breadth-first one level of hops after another, so the crawls of the same website give the same results in the same order")
+	pMaxDepth := flag.Int("max-depth", -1, "Maximum number of hops from the target to the crawled pages (by default, the depth is not limited)")
+	pMaxPages := flag.Int("max-pages", 0, "Maximum number of pages to crawl (0 means no limit)")
+	pSeedSitemap := flag.String("seed-sitemap", "", "Path or URL of the existing sitemap of the website, its pages are crawled first along with the ones found by links")
+	pPriority := flag.String("priority", "", "Weights of URL patterns separated by commas, like /docs/=50,/tag/=-50. Pages with higher weights are crawled first")
+	pImages := flag.Bool("images", false, "Collect images found on pages and add them to the sitemap using image sitemap extension")
+	pVideos := flag.Bool("videos", false, "Detect videos embedded into pages and add them to the sitemap using video sitemap extension")
+	pNews := flag.Bool("news", false, "Also write Google News sitemap with articles published in the last 48 hours next to the main sitemap")
+	pNewsInclude := flag.String("news-include", "", "Patterns separated by commas, only the pages with URLs containing one of them are included in news sitemap")
+	pNewsName := flag.String("news-name", "", "Publication name for news sitemap (by default, it's taken from the page metadata)")
+	pHreflang := flag.Bool("hreflang", false, "Collect language alternates of pages and add them to the sitemap as xhtml:link entries")
+	pHreflangReport := flag.String("hreflang-report", "", "Path to the file to write the report on hreflang problems into (turns on -hreflang)")
+	pGraph := flag.String("graph", "", "Path to the file to write the graph of links between pages into: .dot or .gv for Graphviz, .graphml or .json")
+	pExternal := flag.String("external", "", "Path to the file to write the report on dead and redirected outbound links into. Outbound links are only checked if it's set")
+	pExternalMaxRoutines := flag.Int("external-mr", 4, "Maximum number of outbound links checked at the same time")
+	pExternalRate := flag.Float64("external-rate", 5, "Maximum number of requests per second for checking outbound links")
+	pAssets := flag.String("assets", "", "Path to the file to write the report on missing and slow images, scripts, stylesheets, fonts and media of pages into. Assets are only checked if it's set")
+	pAssetsSlow := flag.Duration("assets-slow", 2*time.Second, "Assets taking longer than this to respond are reported as slow (0 turns it off)")
+	pAssetsMaxRoutines := flag.Int("assets-mr", 4, "Maximum number of assets checked at the same time")
+	pAssetsRate := flag.Float64("assets-rate", 5, "Maximum number of requests per second for checking assets")
+	pFragments := flag.String("fragments", "", "Path to the file to write the report on links whose fragments don't match any element id or name on the target page")
+	pDebugFailures := flag.String("debug-failures", "", "Directory to write every failed HTTP exchange into as .http file, including the beginning of response body")
 	pSearchOpts := flag.String("sp", "", "Search rules for crawler separated by commas. Available options: ignoreTopLevelDomain, includeWithQuery, includeSubdomains")
 	// Then run the parser
 	flag.Parse()
@@ -157,14 +644,119 @@ func getInputData() (*InputData, error) {
 	}
 	inputData.TargetURL = *pTargetURL
 
-	if ot, err := checkOutputFile(*pOutputPath, []string{"XML", "TXT"}); err != nil {
+	format := strings.ToUpper(*pFormat)
+	if format == "MARKDOWN" {
+		format = "MD"
+	}
+	if format != "" {
+		formats := append(append([]string{"XML", "TXT"}, exportTypes...), treeTypes...)
+		if _, err := checkOutputFile("."+format, formats); err != nil {
+			return nil, fmt.Errorf("Output format must be one of these: %s", strings.Join(formats, ", "))
+		}
+	}
+	sitemapFormat := !isExportType(format) && !isTreeType(format)
+
+	if *pOutputPath == stdoutPath {
+		inputData.OutputPath = *pOutputPath
+		inputData.OutputType = format
+		if inputData.OutputType == "" {
+			inputData.OutputType = "XML"
+		}
+	} else if isDir(*pOutputPath) {
+		if !sitemapFormat {
+			return nil, fmt.Errorf("%s output can't be written into directory", format)
+		}
+		if format == "TXT" {
+			return nil, errors.New("Sitemap split into several files can only be written as XML")
+		}
+		inputData.OutputPath = *pOutputPath
+		inputData.OutputType = "DIR"
+		inputData.Gzip = *pGzip
+		inputData.BaseURL = *pBaseURL
+		if inputData.BaseURL == "" {
+			inputData.BaseURL = inputData.TargetURL
+		}
+		if err := validateURL(inputData.BaseURL); err != nil {
+			return nil, err
+		}
+	} else if format != "" {
+		if *pOutputPath == "" {
+			return nil, errors.New("Output file is not specified")
+		}
+		inputData.OutputPath = *pOutputPath
+		inputData.OutputType = format
+		if sitemapFormat && strings.HasSuffix(strings.ToLower(*pOutputPath), ".gz") {
+			inputData.OutputType += ".GZ"
+		}
+	} else if ot, err := checkOutputFile(*pOutputPath, append([]string{"XML", "TXT", "XML.GZ", "TXT.GZ", "HTML", "MD"}, exportTypes...)); err != nil {
 		return nil, err
 	} else {
 		inputData.OutputPath = *pOutputPath
 		inputData.OutputType = ot
 	}
 
-	if wc, err := getWriteCloser(*pLogFile); err != nil {
+	if isTreeType(inputData.OutputType) {
+		sortBy := sitetree.SortBy(strings.ToLower(*pTreeSort))
+		known := false
+		for _, o := range sitetree.SortOrders {
+			known = known || o == sortBy
+		}
+		if !known {
+			return nil, fmt.Errorf("Unknown site tree order %q", *pTreeSort)
+		}
+		inputData.TreeOptions = []sitetree.RenderOption{
+			sitetree.RenderOptionMaxDepth(*pTreeDepth),
+			sitetree.RenderOptionSort(sortBy),
+		}
+	}
+
+	inputData.Lenient = *pLenient
+	inputData.KeepPartial = *pKeepPartial
+	inputData.News = *pNews
+	if inputData.News && inputData.OutputPath == stdoutPath {
+		return nil, errors.New("News sitemap can't be written when the output goes to stdout")
+	}
+	if inputData.News && (isExportType(inputData.OutputType) || isTreeType(inputData.OutputType)) {
+		return nil, fmt.Errorf("News sitemap is written next to the main sitemap, so it can't be used with %s output", inputData.OutputType)
+	}
+	inputData.NewsName = *pNewsName
+	inputData.HreflangReportPath = *pHreflangReport
+	inputData.ExternalReportPath = *pExternal
+	if *pExternalMaxRoutines <= 0 || !isValidRate(*pExternalRate) {
+		return nil, errors.New("Concurrency and rate of outbound link checks must be positive numbers")
+	}
+	inputData.ExternalOptions = []linkcheck.Option{
+		linkcheck.OptionMaxRoutines(uint(*pExternalMaxRoutines)),
+		linkcheck.OptionRate(*pExternalRate),
+	}
+	inputData.FragmentReportPath = *pFragments
+	inputData.AssetReportPath = *pAssets
+	inputData.AssetSlow = *pAssetsSlow
+	if *pAssetsMaxRoutines <= 0 || !isValidRate(*pAssetsRate) {
+		return nil, errors.New("Concurrency and rate of asset checks must be positive numbers")
+	}
+	inputData.AssetOptions = []linkcheck.Option{
+		linkcheck.OptionMaxRoutines(uint(*pAssetsMaxRoutines)),
+		linkcheck.OptionRate(*pAssetsRate),
+	}
+	if *pGraph != "" {
+		gf, err := graph.FormatFromPath(*pGraph)
+		if err != nil {
+			return nil, err
+		}
+		inputData.GraphPath = *pGraph
+		inputData.GraphFormat = gf
+	}
+	inputData.Hreflang = *pHreflang || inputData.HreflangReportPath != ""
+	if *pNewsInclude != "" {
+		inputData.NewsInclude = strings.Split(strings.ReplaceAll(*pNewsInclude, " ", ""), ",")
+	}
+
+	logFallback := os.Stdout
+	if inputData.OutputPath == stdoutPath {
+		logFallback = os.Stderr
+	}
+	if wc, err := getWriteCloser(*pLogFile, logFallback); err != nil {
 		return nil, err
 	} else {
 		inputData.LogWriter = wc
@@ -174,6 +766,49 @@ func getInputData() (*InputData, error) {
 	if *pMaxRoutines > 0 {
 		options = append(options, linkcrawler.OptionMaxRoutines(uint(*pMaxRoutines)))
 	}
+	if *pDeterministic {
+		options = append(options, linkcrawler.OptionDeterministic())
+	}
+	if *pMaxDepth >= 0 {
+		options = append(options, linkcrawler.OptionMaxDepth(uint(*pMaxDepth)))
+	}
+	if *pMaxPages > 0 {
+		options = append(options, linkcrawler.OptionMaxPages(uint(*pMaxPages)))
+	}
+	if *pSeedSitemap != "" {
+		seeds, err := readSitemapSeeds(*pSeedSitemap)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read seed sitemap: %s", err.Error())
+		}
+		options = append(options, linkcrawler.OptionSitemapSeeds(seeds...))
+	}
+	if *pPriority != "" {
+		patterns, err := parsePriorities(*pPriority)
+		if err != nil {
+			return nil, err
+		}
+		weights := linkcrawler.DefaultScoreWeights
+		weights.Patterns = patterns
+		options = append(options, linkcrawler.OptionScore(linkcrawler.NewScoreFunc(weights)))
+	}
+	if *pImages {
+		options = append(options, linkcrawler.OptionCollectImages())
+	}
+	if *pVideos {
+		options = append(options, linkcrawler.OptionCollectVideos())
+	}
+	if inputData.Hreflang {
+		options = append(options, linkcrawler.OptionCollectAlternates())
+	}
+	if inputData.AssetReportPath != "" {
+		options = append(options, linkcrawler.OptionCollectAssets())
+	}
+	if inputData.FragmentReportPath != "" {
+		options = append(options, linkcrawler.OptionCollectAnchors())
+	}
+	if *pDebugFailures != "" {
+		options = append(options, linkcrawler.OptionDebugDir(*pDebugFailures))
+	}
 	searchOptions, err := parseSearchOptions(*pSearchOpts)
 	if err != nil {
 		return nil, err
@@ -184,9 +819,45 @@ func getInputData() (*InputData, error) {
 	return &inputData, nil
 }
 
-func getWriteCloser(path string) (io.WriteCloser, error) {
+// confirmPartial asks the user if the output should be written with the pages found before the crawl was interrupted.
+// Unless -keep-partial is given, the output is dropped if there is no terminal to ask or the user interrupts the question
+func confirmPartial(inputData *InputData, statusBar *gost.StatusBar, tickRate time.Duration, pages int, stopSigs <-chan os.Signal) bool {
+	if inputData.KeepPartial {
+		statusBar.Printf("Crawl interrupted, writing the output with %d pages found so far", pages)
+		return true
+	}
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) || !terminal.IsTerminal(int(os.Stderr.Fd())) {
+		return false
+	}
+	// Let the status bar clear its line so it doesn't overwrite the question
+	statusBar.Close()
+	time.Sleep(2 * tickRate)
+	what := "sitemap"
+	if isExportType(inputData.OutputType) {
+		what = "crawl results"
+	} else if isTreeType(inputData.OutputType) {
+		what = "site tree"
+	}
+	fmt.Fprintf(os.Stderr, "Crawl interrupted with %d pages found. Write the partial %s? [Y/n] ", pages, what)
+	answers := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answers <- line
+	}()
+	select {
+	case answer := <-answers:
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "" || answer == "y" || answer == "yes"
+	case <-stopSigs:
+		fmt.Fprintln(os.Stderr)
+		return false
+	}
+}
+
+// getWriteCloser creates the file at path, or returns fallback if the path is empty
+func getWriteCloser(path string, fallback *os.File) (io.WriteCloser, error) {
 	if path == "" {
-		return os.Stdout, nil
+		return fallback, nil
 	}
 
 	f, err := os.Create(path)
@@ -197,6 +868,15 @@ func getWriteCloser(path string) (io.WriteCloser, error) {
 	return f, nil
 }
 
+// isDir checks if the path names an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.IsDir()
+}
+
 func checkOutputFile(path string, allowedTypes []string) (string, error) {
 	// Uppercase allowedTypes for convenience
 	types := make([]string, len(allowedTypes))
@@ -225,9 +905,14 @@ func checkOutputFile(path string, allowedTypes []string) (string, error) {
 	return fExt, nil
 }
 
+// getExtension returns the file extension of path. Compressed files get the double extension like "xml.gz"
 func getExtension(path string) string {
 	parts := strings.Split(path, ".")
-	return parts[len(parts)-1]
+	ext := parts[len(parts)-1]
+	if strings.EqualFold(ext, "gz") && len(parts) > 2 {
+		ext = parts[len(parts)-2] + "." + ext
+	}
+	return ext
 }
 
 func validateURL(urlString string) error {
@@ -273,3 +958,42 @@ func parseSearchOptions(input string) ([]linkcrawler.Option, error) {
 	}
 	return options, nil
 }
+
+// isValidRate checks if the rate of requests per second is a positive finite number
+func isValidRate(rate float64) bool {
+	return rate > 0 && !math.IsInf(rate, 1)
+}
+
+// parsePriorities parses the weights of URL patterns given as pattern=weight pairs separated by commas
+func parsePriorities(input string) (map[string]float64, error) {
+	patterns := make(map[string]float64)
+	for _, pair := range strings.Split(input, ",") {
+		pair = strings.TrimSpace(pair)
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("Priority must be given as pattern=weight: %s", pair)
+		}
+		weight, err := strconv.ParseFloat(pair[i+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid weight of priority pattern %s: %s", pair[:i], pair[i+1:])
+		}
+		patterns[pair[:i]] = weight
+	}
+	return patterns, nil
+}
+
+// readSitemapSeeds reads the URLs of the sitemap from local file or from the web if src is http(s) URL
+func readSitemapSeeds(src string) ([]string, error) {
+	r, err := openSource(src)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	seeds := make([]string, 0)
+	err = sitemap.ReadUrls(r, func(u sitemap.Url) error {
+		seeds = append(seeds, u.Loc)
+		return nil
+	})
+	return seeds, err
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/sitemap"
+)
+
+// newsSitemap collects the recently published articles into Google News sitemap written next to the main sitemap
+type newsSitemap struct {
+	Path    string
+	writer  *sitemap.Writer
+	file    *pendingFile
+	include []string
+	name    string
+	now     time.Time
+}
+
+// getNewsPath makes the path of news sitemap based on the main output path
+func getNewsPath(inputData *InputData) string {
+	if inputData.OutputType == "DIR" {
+		return filepath.Join(inputData.OutputPath, "sitemap-news.xml")
+	}
+	ext := "." + getExtension(inputData.OutputPath)
+	base := strings.TrimSuffix(inputData.OutputPath, ext)
+	if strings.HasSuffix(inputData.OutputType, ".GZ") {
+		return base + "-news.xml.gz"
+	}
+	return base + "-news.xml"
+}
+
+func openNewsSitemap(inputData *InputData) (*newsSitemap, error) {
+	path := getNewsPath(inputData)
+	f, err := createPendingFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	writeOptions := []sitemap.WriteOption{sitemap.WriteOptionLimits(sitemap.MaxNewsUrls, 0)}
+	if strings.HasSuffix(path, ".gz") {
+		writeOptions = append(writeOptions, sitemap.WriteOptionGzip())
+	}
+	if inputData.Lenient {
+		writeOptions = append(writeOptions, sitemap.WriteOptionLenient())
+	}
+	return &newsSitemap{
+		Path:    path,
+		writer:  sitemap.NewWriter(f, writeOptions...),
+		file:    f,
+		include: inputData.NewsInclude,
+		name:    inputData.NewsName,
+		now:     time.Now(),
+	}, nil
+}
+
+// getNewsLanguage converts the language of the page into the form required by news sitemap
+func getNewsLanguage(lang string) string {
+	lang = strings.ToLower(strings.Replace(lang, "_", "-", -1))
+	if lang == "zh-cn" || lang == "zh-tw" {
+		return lang
+	}
+	return strings.Split(lang, "-")[0]
+}
+
+// Add writes the page into news sitemap if it matches include rules and was published within the allowed period.
+// Returned flag tells if the page was added
+func (ns *newsSitemap) Add(res linkcrawler.SearchResult) (bool, error) {
+	if !sitemap.IsRecentNews(res.Meta.Published, ns.now) {
+		return false, nil
+	}
+	if len(ns.include) > 0 {
+		matched := false
+		for _, p := range ns.include {
+			if strings.Contains(res.Addr, p) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	name := ns.name
+	if name == "" {
+		name = res.Meta.SiteName
+	}
+	if name == "" {
+		if u, err := url.Parse(res.Addr); err == nil {
+			name = u.Hostname()
+		}
+	}
+
+	u := sitemap.NewUrl(res.Addr, "", "", 0.0)
+	u.News = sitemap.NewNews(name, getNewsLanguage(res.Meta.Lang), res.Meta.Published, res.Meta.Title)
+	if err := ns.writer.Add(*u); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Count returns the amount of articles in news sitemap
+func (ns *newsSitemap) Count() int {
+	return ns.writer.Count()
+}
+
+// Close finishes news sitemap and moves it to its path
+func (ns *newsSitemap) Close() error {
+	if err := ns.writer.Close(); err != nil {
+		ns.file.Discard()
+		return err
+	}
+	return ns.file.Commit()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// pendingOutput is the output written under a temporary name that takes the place of the target only when it's complete,
+// so the crawl stopped halfway never leaves truncated files behind
+type pendingOutput interface {
+	// Commit moves the output to its target path
+	Commit() error
+	// Discard removes the output unless it's committed
+	Discard()
+}
+
+// pendingFile is the output file written next to its target path
+type pendingFile struct {
+	*os.File
+	// path is the target path, it's empty once the file is committed or discarded, and for stdout
+	path string
+}
+
+// createPendingFile creates the temporary file in the directory of path
+func createPendingFile(path string) (*pendingFile, error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	// Temporary files are only readable by the owner, unlike the ones made by os.Create
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &pendingFile{File: f, path: path}, nil
+}
+
+// Commit closes the file and renames it to the target path. Nothing is done for stdout
+func (pf *pendingFile) Commit() error {
+	if pf.path == "" {
+		return nil
+	}
+	if err := pf.File.Close(); err != nil {
+		pf.Discard()
+		return err
+	}
+	if err := os.Rename(pf.Name(), pf.path); err != nil {
+		pf.Discard()
+		return err
+	}
+	pf.path = ""
+	return nil
+}
+
+// Discard closes and removes the file unless it's committed. Nothing is done for stdout
+func (pf *pendingFile) Discard() {
+	if pf.path == "" {
+		return
+	}
+	pf.File.Close()
+	os.Remove(pf.Name())
+	pf.path = ""
+}
+
+// pendingDir is the temporary directory inside the target one, its files are moved to the target on commit
+type pendingDir struct {
+	Path string
+	// target is the target directory, it's empty once the directory is committed or discarded
+	target string
+}
+
+// createPendingDir creates the temporary directory inside the existing directory dir
+func createPendingDir(dir string) (*pendingDir, error) {
+	path, err := ioutil.TempDir(dir, ".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	return &pendingDir{Path: path, target: dir}, nil
+}
+
+// Commit moves the files to the target directory, replacing the files with the same names, and removes the temporary directory
+func (pd *pendingDir) Commit() error {
+	if pd.target == "" {
+		return nil
+	}
+	files, err := ioutil.ReadDir(pd.Path)
+	if err != nil {
+		pd.Discard()
+		return err
+	}
+	for _, f := range files {
+		if err := os.Rename(filepath.Join(pd.Path, f.Name()), filepath.Join(pd.target, f.Name())); err != nil {
+			pd.Discard()
+			return err
+		}
+	}
+	pd.target = ""
+	return os.Remove(pd.Path)
+}
+
+// Discard removes the temporary directory with its files unless it's committed
+func (pd *pendingDir) Discard() {
+	if pd.target == "" {
+		return
+	}
+	os.RemoveAll(pd.Path)
+	pd.target = ""
+}
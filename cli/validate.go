@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/TofuOverdose/WebMapMaker/sitemap"
+)
+
+// runValidate implements the "validate" command, which checks sitemaps given by file paths or URLs against the protocol.
+// It returns the exit code: 0 if all sitemaps are valid, 1 if violations were found and 2 if some sitemap couldn't be read
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: makemap validate <file or URL>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	code := 0
+	for _, src := range fs.Args() {
+		violations, err := validateSource(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", src, err.Error())
+			code = 2
+			continue
+		}
+		if len(violations) == 0 {
+			fmt.Printf("%s: OK\n", src)
+			continue
+		}
+		for _, v := range violations {
+			fmt.Printf("%s: %s\n", src, v)
+		}
+		if code == 0 {
+			code = 1
+		}
+	}
+	return code
+}
+
+// validateSource reads sitemap either from local file or from the web if src is http(s) URL
+func validateSource(src string) ([]sitemap.Violation, error) {
+	r, err := openSource(src)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return sitemap.Validate(r)
+}
+
+// openSource opens sitemap either from local file or from the web if src is http(s) URL
+func openSource(src string) (io.ReadCloser, error) {
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		res, err := http.Get(src)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode >= 400 {
+			res.Body.Close()
+			return nil, fmt.Errorf("Failed to fetch sitemap: %s", res.Status)
+		}
+		return res.Body, nil
+	}
+	return os.Open(src)
+}
@@ -0,0 +1,78 @@
+// This example crawls the website behind basic authentication with a custom Fetcher and hooks, and prints the URLs of its pages:
+//
+//	go run ./examples/fetcher https://staging.example.com user password
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+)
+
+// authFetcher loads pages with the credentials and its own User-Agent
+type authFetcher struct {
+	client   *http.Client
+	user     string
+	password string
+}
+
+func (af *authFetcher) Fetch(ctx context.Context, addr string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The headers set by OnRequest hooks go first, so the credentials can't be overridden by them
+	for name, values := range linkcrawler.RequestHeader(ctx) {
+		req.Header[name] = values
+	}
+	req.SetBasicAuth(af.user, af.password)
+	req.Header.Set("User-Agent", "cms-publisher/1.0")
+	res, err := af.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 400 {
+		// Failed responses are returned as FetchError so the crawler reports them with their status
+		res.Body.Close()
+		return nil, &linkcrawler.FetchError{
+			Code:        res.StatusCode,
+			Status:      res.Status,
+			RequestURLs: []string{addr, res.Request.URL.String()},
+			Header:      res.Header,
+		}
+	}
+	return res, nil
+}
+
+func main() {
+	if len(os.Args) != 4 {
+		log.Fatal("Usage: fetcher <URL> <user> <password>")
+	}
+
+	fetcher := &authFetcher{client: &http.Client{}, user: os.Args[2], password: os.Args[3]}
+	results, err := linkcrawler.Crawl(context.Background(), os.Args[1],
+		linkcrawler.OptionFetcher(fetcher),
+		// Pages of the admin area are never crawled, even if they are linked
+		linkcrawler.OptionOnLinkDiscovered(func(ctx context.Context, d *linkcrawler.Discovery) bool {
+			return !strings.HasPrefix(d.URL.Path, "/admin/")
+		}),
+		linkcrawler.OptionOnRequest(func(ctx context.Context, req *linkcrawler.Request) {
+			req.Header.Set("X-Crawl-Referrer", req.Referrer)
+		}),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for res := range results {
+		if res.Error != nil {
+			fmt.Printf("%s\t%s\n", res.Addr, res.Category)
+			continue
+		}
+		fmt.Printf("%s\t%d\n", res.Addr, res.Status)
+	}
+}
@@ -0,0 +1,55 @@
+// This example reads the existing sitemap of the website, crawls the website starting from the pages listed there,
+// and prints the pages missing from the sitemap:
+//
+//	go run ./examples/seeds https://example.com sitemap.xml
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/sitemap"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		log.Fatal("Usage: seeds <URL> <sitemap file>")
+	}
+
+	f, err := os.Open(os.Args[2])
+	if err != nil {
+		log.Fatal(err)
+	}
+	listed := make(map[string]bool)
+	seeds := make([]string, 0)
+	// The entries are read one by one, so even the sitemaps of huge websites are never kept in memory as a whole
+	err = sitemap.ReadUrls(f, func(u sitemap.Url) error {
+		listed[u.Loc] = true
+		seeds = append(seeds, u.Loc)
+		return nil
+	})
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Pages of the docs are taken before the other waiting pages, so most of the page cap is spent on them
+	weights := linkcrawler.DefaultScoreWeights
+	weights.Patterns = map[string]float64{"/docs/": 50}
+	results, err := linkcrawler.Crawl(context.Background(), os.Args[1],
+		linkcrawler.OptionSitemapSeeds(seeds...),
+		linkcrawler.OptionScore(linkcrawler.NewScoreFunc(weights)),
+		linkcrawler.OptionMaxPages(10000),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for res := range results {
+		if res.Error == nil && !listed[res.Addr] {
+			fmt.Printf("%s (linked from %s)\n", res.Addr, res.Referrer)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// This example crawls the website and writes its sitemap to stdout:
+//
+//	go run ./examples/sitemap https://example.com
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/sitemap"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("Usage: sitemap <URL>")
+	}
+
+	// The crawl is aborted if it takes too long, the sitemap is written with the pages found by then
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	results, err := linkcrawler.Crawl(ctx, os.Args[1],
+		linkcrawler.OptionMaxRoutines(8),
+		linkcrawler.OptionCollectImages(),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := sitemap.NewWriter(os.Stdout)
+	for res := range results {
+		if res.Error != nil {
+			fmt.Fprintf(os.Stderr, "%s: [%s] %s\n", res.Addr, res.Category, res.Error.Error())
+			continue
+		}
+		lastmod := ""
+		if t, err := http.ParseTime(res.Headers.Get("Last-Modified")); err == nil {
+			lastmod = sitemap.FormatTime(t)
+		}
+		u := sitemap.NewUrl(res.Addr, lastmod, "", 0.0)
+		for _, img := range res.Images {
+			u.Images = append(u.Images, *sitemap.NewImage(img.URL.String(), img.Title, img.Caption))
+		}
+		if err := w.Add(*u); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,221 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+)
+
+// Format is the machine-readable format crawl results are exported in
+type Format string
+
+const (
+	// FormatJSON writes all results as a single JSON array
+	FormatJSON Format = "json"
+	// FormatNDJSON writes every result as a JSON object on its own line, so it can be consumed while the crawl goes on
+	FormatNDJSON Format = "ndjson"
+	// FormatCSV writes results as CSV table with the header line
+	FormatCSV Format = "csv"
+)
+
+// Formats lists all supported export formats
+var Formats = []Format{FormatJSON, FormatNDJSON, FormatCSV}
+
+// ParseFormat returns the format with the given name, the name is case-insensitive
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown export format %q", name)
+}
+
+// Record is the flat form of linkcrawler.SearchResult written by exporters
+type Record struct {
+	URL           string `json:"url"`
+	FinalURL      string `json:"final_url"`
+	Hops          int    `json:"hops"`
+	Status        int    `json:"status,omitempty"`
+	ContentType   string `json:"content_type,omitempty"`
+	ContentLength int64  `json:"content_length"`
+	// TTFB and DownloadTime are given in milliseconds
+	TTFB         float64           `json:"ttfb_ms"`
+	DownloadTime float64           `json:"download_ms"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Category     string            `json:"category,omitempty"`
+	Source       string            `json:"source"`
+	Referrer     string            `json:"referrer,omitempty"`
+}
+
+// csvHeader names the columns of CSV export in the order of Record fields
+var csvHeader = []string{"url", "final_url", "hops", "status", "content_type", "content_length", "ttfb_ms", "download_ms", "headers", "error", "category", "source", "referrer"}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// NewRecord makes a Record from the search result. Headers with several values are joined with commas
+func NewRecord(res linkcrawler.SearchResult) Record {
+	r := Record{
+		URL:           res.Addr,
+		FinalURL:      res.FinalURL,
+		Hops:          res.Hops,
+		Status:        res.Status,
+		ContentType:   res.ContentType,
+		ContentLength: res.ContentLength,
+		TTFB:          milliseconds(res.TTFB),
+		DownloadTime:  milliseconds(res.DownloadTime),
+		Source:        string(res.Source),
+		Referrer:      res.Referrer,
+	}
+	if len(res.Headers) > 0 {
+		r.Headers = make(map[string]string, len(res.Headers))
+		for name, values := range res.Headers {
+			r.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+	if res.Error != nil {
+		r.Error = res.Error.Error()
+		r.Category = string(res.Category)
+	}
+	return r
+}
+
+func (r Record) csvRow() []string {
+	status := ""
+	if r.Status != 0 {
+		status = strconv.Itoa(r.Status)
+	}
+	// Headers are written into a single column as "Name: value" pairs separated with semicolons
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := make([]string, len(names))
+	for i, name := range names {
+		headers[i] = name + ": " + r.Headers[name]
+	}
+	return []string{
+		r.URL,
+		r.FinalURL,
+		strconv.Itoa(r.Hops),
+		status,
+		r.ContentType,
+		strconv.FormatInt(r.ContentLength, 10),
+		strconv.FormatFloat(r.TTFB, 'f', -1, 64),
+		strconv.FormatFloat(r.DownloadTime, 'f', -1, 64),
+		strings.Join(headers, "; "),
+		r.Error,
+		r.Category,
+		r.Source,
+		r.Referrer,
+	}
+}
+
+// ResultWriter writes search results into the underlying writer as they arrive.
+// Close must be called after the last result to complete the output, it doesn't close the underlying writer
+type ResultWriter interface {
+	Write(res linkcrawler.SearchResult) error
+	Close() error
+}
+
+// NewResultWriter makes a ResultWriter writing into w in the given format
+func NewResultWriter(w io.Writer, format Format) (ResultWriter, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("Unknown export format %q", format)
+}
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (jw *jsonWriter) Write(res linkcrawler.SearchResult) error {
+	data, err := json.Marshal(NewRecord(res))
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if jw.count == 0 {
+		sep = "[\n  "
+	}
+	if _, err := io.WriteString(jw.w, sep); err != nil {
+		return err
+	}
+	if _, err := jw.w.Write(data); err != nil {
+		return err
+	}
+	jw.count++
+	return nil
+}
+
+func (jw *jsonWriter) Close() error {
+	end := "\n]\n"
+	if jw.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(res linkcrawler.SearchResult) error {
+	return nw.enc.Encode(NewRecord(res))
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.headerWritten {
+		return nil
+	}
+	cw.headerWritten = true
+	return cw.w.Write(csvHeader)
+}
+
+func (cw *csvWriter) Write(res linkcrawler.SearchResult) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	if err := cw.w.Write(NewRecord(res).csvRow()); err != nil {
+		return err
+	}
+	// Rows are flushed right away so the output can be consumed while the crawl goes on
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
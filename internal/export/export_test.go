@@ -0,0 +1,116 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+)
+
+func testResults() []linkcrawler.SearchResult {
+	return []linkcrawler.SearchResult{
+		{
+			Addr:          "https://example.com/",
+			FinalURL:      "https://example.com/",
+			Status:        200,
+			Source:        linkcrawler.SourceStart,
+			ContentType:   "text/html",
+			ContentLength: 1024,
+			TTFB:          1500 * time.Microsecond,
+			DownloadTime:  3 * time.Millisecond,
+			Headers:       http.Header{"Cache-Control": {"no-cache", "private"}, "Server": {"nginx"}},
+		},
+		{
+			Addr:          "https://example.com/missing",
+			FinalURL:      "https://example.com/missing",
+			Hops:          1,
+			Status:        404,
+			Source:        linkcrawler.SourceLink,
+			Referrer:      "https://example.com/",
+			ContentLength: -1,
+			Error:         errors.New("Not Found"),
+			Category:      linkcrawler.CategoryClientError,
+		},
+	}
+}
+
+func writeResults(t *testing.T, format Format, results []linkcrawler.SearchResult) string {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	rw, err := NewResultWriter(buf, format)
+	if err != nil {
+		t.Fatalf("NewResultWriter failed: %v", err)
+	}
+	for _, res := range results {
+		if err := rw.Write(res); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := rw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"json", FormatJSON, false},
+		{"NDJSON", FormatNDJSON, false},
+		{"Csv", FormatCSV, false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.name)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		results []linkcrawler.SearchResult
+		want    string
+	}{
+		{"json", FormatJSON, testResults(), `[
+  {"url":"https://example.com/","final_url":"https://example.com/","hops":0,"status":200,"content_type":"text/html","content_length":1024,"ttfb_ms":1.5,"download_ms":3,"headers":{"Cache-Control":"no-cache, private","Server":"nginx"},"source":"start"},
+  {"url":"https://example.com/missing","final_url":"https://example.com/missing","hops":1,"status":404,"content_length":-1,"ttfb_ms":0,"download_ms":0,"error":"Not Found","category":"client error","source":"link","referrer":"https://example.com/"}
+]
+`},
+		{"empty json", FormatJSON, nil, "[]\n"},
+		{"ndjson", FormatNDJSON, testResults(), `{"url":"https://example.com/","final_url":"https://example.com/","hops":0,"status":200,"content_type":"text/html","content_length":1024,"ttfb_ms":1.5,"download_ms":3,"headers":{"Cache-Control":"no-cache, private","Server":"nginx"},"source":"start"}
+{"url":"https://example.com/missing","final_url":"https://example.com/missing","hops":1,"status":404,"content_length":-1,"ttfb_ms":0,"download_ms":0,"error":"Not Found","category":"client error","source":"link","referrer":"https://example.com/"}
+`},
+		{"csv", FormatCSV, testResults(), `url,final_url,hops,status,content_type,content_length,ttfb_ms,download_ms,headers,error,category,source,referrer
+https://example.com/,https://example.com/,0,200,text/html,1024,1.5,3,"Cache-Control: no-cache, private; Server: nginx",,,start,
+https://example.com/missing,https://example.com/missing,1,404,,-1,0,0,,Not Found,client error,link,https://example.com/
+`},
+		{"empty csv", FormatCSV, nil, "url,final_url,hops,status,content_type,content_length,ttfb_ms,download_ms,headers,error,category,source,referrer\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writeResults(t, tt.format, tt.results)
+			if got != tt.want {
+				t.Errorf("output is:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if tt.format == FormatJSON {
+				var records []Record
+				if err := json.Unmarshal([]byte(got), &records); err != nil || len(records) != len(tt.results) {
+					t.Errorf("output is not a JSON array of %d records: %v", len(tt.results), err)
+				}
+			}
+		})
+	}
+}
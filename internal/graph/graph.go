@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+)
+
+// Node is a page of the link graph
+type Node struct {
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+	Status int    `json:"status,omitempty"`
+	// Crawled tells if the page was visited by crawler. Pages outside of the crawl only appear as link targets
+	Crawled bool `json:"crawled"`
+}
+
+// Edge is a hyperlink from one page to another
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Anchor is the text of the link
+	Anchor string `json:"anchor,omitempty"`
+	// Rel holds the link types from rel attribute, like "nofollow"
+	Rel []string `json:"rel,omitempty"`
+}
+
+// Graph is the directed graph of links between pages. Every link found on the crawled pages is an edge, so there might be several edges between two pages
+type Graph struct {
+	Edges []Edge
+	nodes map[string]*Node
+}
+
+// NewGraph makes an empty Graph
+func NewGraph() *Graph {
+	return &Graph{
+		Edges: make([]Edge, 0),
+		nodes: make(map[string]*Node),
+	}
+}
+
+// nodeID identifies the page by its URL without the fragment, so links to the sections of the page lead to the page itself
+func nodeID(u url.URL) string {
+	u.Fragment = ""
+	return u.String()
+}
+
+func (g *Graph) node(id string) *Node {
+	n, ok := g.nodes[id]
+	if !ok {
+		n = &Node{URL: id}
+		g.nodes[id] = n
+	}
+	return n
+}
+
+// Add records the crawled page and its outgoing links
+func (g *Graph) Add(res linkcrawler.SearchResult) {
+	u, err := url.Parse(res.Addr)
+	if err != nil {
+		return
+	}
+	source := g.node(nodeID(*u))
+	if source.Crawled && res.Error != nil {
+		// Errors of parsing particular links are reported separately from the page itself
+		return
+	}
+	source.Crawled = true
+	source.Status = res.Status
+	source.Title = res.Meta.Title
+
+	for _, link := range res.Links {
+		if link.URL.Scheme != "http" && link.URL.Scheme != "https" {
+			continue
+		}
+		target := g.node(nodeID(link.URL))
+		g.Edges = append(g.Edges, Edge{
+			Source: source.URL,
+			Target: target.URL,
+			Anchor: link.Name,
+			Rel:    link.Rel,
+		})
+	}
+}
+
+// Nodes returns all pages of the graph sorted by URL
+func (g *Graph) Nodes() []Node {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, *n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].URL < nodes[j].URL
+	})
+	return nodes
+}
+
+// SortedEdges returns the edges ordered by source and target, so the output doesn't depend on the order pages were crawled in
+func (g *Graph) SortedEdges() []Edge {
+	edges := make([]Edge, len(g.Edges))
+	copy(edges, g.Edges)
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Target < edges[j].Target
+	})
+	return edges
+}
+
+func relString(rel []string) string {
+	return strings.Join(rel, " ")
+}
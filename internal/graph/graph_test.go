@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+func testLink(t *testing.T, name, href string, rel ...string) links.Link {
+	t.Helper()
+	u, err := url.Parse(href)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return links.Link{Name: name, URL: *u, Rel: rel}
+}
+
+func testGraph(t *testing.T) *Graph {
+	t.Helper()
+	g := NewGraph()
+	g.Add(linkcrawler.SearchResult{Addr: "https://example.com/", Status: 200, Meta: links.Meta{Title: "Home"}, Links: []links.Link{
+		testLink(t, "About", "https://example.com/about"),
+		testLink(t, "Partner", "https://example.org/", "nofollow"),
+		testLink(t, "Mail", "mailto:info@example.com"),
+	}})
+	g.Add(linkcrawler.SearchResult{Addr: "https://example.com/about", Status: 404, Error: errors.New("Not Found")})
+	g.Add(linkcrawler.SearchResult{Addr: "https://example.com/team", Status: 200, Links: []links.Link{
+		testLink(t, "Home", "https://example.com/#top"),
+		testLink(t, `"About"`, "https://example.com/about"),
+	}})
+	// The errors of parsing links come after the page itself and must not change it
+	g.Add(linkcrawler.SearchResult{Addr: "https://example.com/team", Status: 200, Error: errors.New("Failed to parse href")})
+	return g
+}
+
+func TestGraph(t *testing.T) {
+	g := testGraph(t)
+	wantNodes := []Node{
+		{URL: "https://example.com/", Title: "Home", Status: 200, Crawled: true},
+		{URL: "https://example.com/about", Status: 404, Crawled: true},
+		{URL: "https://example.com/team", Status: 200, Crawled: true},
+		{URL: "https://example.org/"},
+	}
+	nodes := g.Nodes()
+	if len(nodes) != len(wantNodes) {
+		t.Fatalf("got nodes %+v, want %+v", nodes, wantNodes)
+	}
+	for i := range nodes {
+		if nodes[i] != wantNodes[i] {
+			t.Errorf("node %d is %+v, want %+v", i, nodes[i], wantNodes[i])
+		}
+	}
+	wantEdges := [][2]string{
+		{"https://example.com/", "https://example.com/about"},
+		{"https://example.com/", "https://example.org/"},
+		{"https://example.com/team", "https://example.com/"},
+		{"https://example.com/team", "https://example.com/about"},
+	}
+	edges := g.SortedEdges()
+	if len(edges) != len(wantEdges) {
+		t.Fatalf("got edges %+v, want %v", edges, wantEdges)
+	}
+	for i, e := range edges {
+		if e.Source != wantEdges[i][0] || e.Target != wantEdges[i][1] {
+			t.Errorf("edge %d is %+v, want %v", i, e, wantEdges[i])
+		}
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    Format
+		wantErr bool
+	}{
+		{"links.dot", FormatDOT, false},
+		{"out/links.GV", FormatDOT, false},
+		{"links.graphml", FormatGraphML, false},
+		{"links.json", FormatJSON, false},
+		{"links.txt", "", true},
+		{"links", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := FormatFromPath(tt.path)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := testGraph(t).Write(buf, FormatDOT); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := `digraph links {
+  "https://example.com/" [label="Home", URL="https://example.com/"];
+  "https://example.com/about" [label="https://example.com/about", URL="https://example.com/about", color=red];
+  "https://example.com/team" [label="https://example.com/team", URL="https://example.com/team"];
+  "https://example.org/" [label="https://example.org/", URL="https://example.org/", style=dashed];
+  "https://example.com/" -> "https://example.com/about" [label="About"];
+  "https://example.com/" -> "https://example.org/" [label="Partner", rel="nofollow"];
+  "https://example.com/team" -> "https://example.com/" [label="Home"];
+  "https://example.com/team" -> "https://example.com/about" [label="\"About\""];
+}
+`
+	if buf.String() != want {
+		t.Errorf("graph is:\n%s\nwant:\n%s", buf, want)
+	}
+}
+
+func TestWriteGraphMLAndJSON(t *testing.T) {
+	g := testGraph(t)
+
+	buf := &bytes.Buffer{}
+	if err := g.Write(buf, FormatGraphML); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var doc struct {
+		Nodes []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("GraphML is malformed: %v\n%s", err, buf)
+	}
+	if len(doc.Nodes) != 4 || len(doc.Edges) != 4 {
+		t.Errorf("GraphML has %d nodes and %d edges, want 4 and 4:\n%s", len(doc.Nodes), len(doc.Edges), buf)
+	}
+
+	buf.Reset()
+	if err := g.Write(buf, FormatJSON); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var data struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatalf("JSON is malformed: %v", err)
+	}
+	if len(data.Nodes) != 4 || len(data.Edges) != 4 || data.Edges[1].Rel[0] != "nofollow" {
+		t.Errorf("JSON is:\n%s", buf)
+	}
+}
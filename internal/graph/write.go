@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is the file format the graph is exported in
+type Format string
+
+const (
+	// FormatDOT is the format of Graphviz
+	FormatDOT Format = "dot"
+	// FormatGraphML is XML-based format supported by Gephi, yEd and others
+	FormatGraphML Format = "graphml"
+	// FormatJSON writes the lists of nodes and edges as JSON object
+	FormatJSON Format = "json"
+)
+
+// FormatFromPath picks the format by file extension: .dot or .gv, .graphml or .json
+func FormatFromPath(path string) (Format, error) {
+	parts := strings.Split(path, ".")
+	switch strings.ToLower(parts[len(parts)-1]) {
+	case "dot", "gv":
+		return FormatDOT, nil
+	case "graphml":
+		return FormatGraphML, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("Link graph file extension must be one of these: .dot, .gv, .graphml, .json")
+}
+
+// Write writes the graph into w in the given format
+func (g *Graph) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatDOT:
+		return g.WriteDOT(w)
+	case FormatGraphML:
+		return g.WriteGraphML(w)
+	case FormatJSON:
+		return g.WriteJSON(w)
+	}
+	return fmt.Errorf("Unknown link graph format %q", format)
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotString(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// WriteDOT writes the graph into w in Graphviz DOT language. Pages outside of the crawl are drawn dashed, pages returning errors are red
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph links {")
+	for _, n := range g.Nodes() {
+		label := n.Title
+		if label == "" {
+			label = n.URL
+		}
+		attrs := fmt.Sprintf("label=%s, URL=%s", dotString(label), dotString(n.URL))
+		if !n.Crawled {
+			attrs += ", style=dashed"
+		}
+		if n.Status >= 400 {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(bw, "  %s [%s];\n", dotString(n.URL), attrs)
+	}
+	for _, e := range g.SortedEdges() {
+		attrs := fmt.Sprintf("label=%s", dotString(e.Anchor))
+		if len(e.Rel) > 0 {
+			attrs += fmt.Sprintf(", rel=%s", dotString(relString(e.Rel)))
+		}
+		fmt.Fprintf(bw, "  %s -> %s [%s];\n", dotString(e.Source), dotString(e.Target), attrs)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphML struct {
+	XMLName   xml.Name     `xml:"graphml"`
+	Namespace string       `xml:"xmlns,attr"`
+	Keys      []graphMLKey `xml:"key"`
+	Graph     struct {
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// WriteGraphML writes the graph into w in GraphML format. Node titles, statuses, anchor texts and rel attributes are written as data attributes
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	doc := graphML{
+		Namespace: graphMLNamespace,
+		Keys: []graphMLKey{
+			{ID: "url", For: "node", Name: "url", Type: "string"},
+			{ID: "title", For: "node", Name: "title", Type: "string"},
+			{ID: "status", For: "node", Name: "status", Type: "int"},
+			{ID: "crawled", For: "node", Name: "crawled", Type: "boolean"},
+			{ID: "anchor", For: "edge", Name: "anchor", Type: "string"},
+			{ID: "rel", For: "edge", Name: "rel", Type: "string"},
+		},
+	}
+	doc.Graph.EdgeDefault = "directed"
+	for _, n := range g.Nodes() {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.URL,
+			Data: []graphMLData{
+				{Key: "url", Value: n.URL},
+				{Key: "title", Value: n.Title},
+				{Key: "status", Value: fmt.Sprint(n.Status)},
+				{Key: "crawled", Value: fmt.Sprint(n.Crawled)},
+			},
+		})
+	}
+	for _, e := range g.SortedEdges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data: []graphMLData{
+				{Key: "anchor", Value: e.Anchor},
+				{Key: "rel", Value: relString(e.Rel)},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJSON writes the graph into w as JSON object with "nodes" and "edges" lists
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
+	}{g.Nodes(), g.SortedEdges()})
+}
@@ -0,0 +1,244 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRoutines  = 4
+	defaultRate         = 5
+	defaultTimeout      = 15 * time.Second
+	defaultMaxRedirects = 10
+)
+
+// Result is the outcome of checking a single link
+type Result struct {
+	URL string
+	// Status is the HTTP status code of the final response, it's 0 if the request failed without response
+	Status int
+	// RedirectURL is where the link finally leads to, it's empty if the link was not redirected
+	RedirectURL string
+	Error       error
+	// Duration is the time the final request took, including the redirects
+	Duration time.Duration
+}
+
+// IsDead tells if the link failed to load
+func (r Result) IsDead() bool {
+	return r.Error != nil || r.Status >= 400
+}
+
+// IsRedirected tells if the link leads to another URL
+func (r Result) IsRedirected() bool {
+	return r.RedirectURL != ""
+}
+
+type checkConfig struct {
+	maxRoutines uint
+	rate        float64
+	timeout     time.Duration
+}
+
+// Option configures the Checker
+type Option func(*checkConfig)
+
+// OptionMaxRoutines sets the maximum number of links checked at the same time. Default value is 4
+func OptionMaxRoutines(num uint) Option {
+	return func(cc *checkConfig) {
+		if num > 0 {
+			cc.maxRoutines = num
+		}
+	}
+}
+
+// OptionRate sets the maximum number of requests per second, values that are not positive finite numbers are ignored. Default value is 5
+func OptionRate(perSecond float64) Option {
+	return func(cc *checkConfig) {
+		if perSecond > 0 && !math.IsInf(perSecond, 1) {
+			cc.rate = perSecond
+		}
+	}
+}
+
+// interval returns the time between requests for the rate. It's at least a nanosecond, since the ticker can't go faster
+func (cc *checkConfig) interval() time.Duration {
+	interval := time.Duration(float64(time.Second) / cc.rate)
+	if interval < time.Nanosecond {
+		return time.Nanosecond
+	}
+	return interval
+}
+
+// OptionTimeout sets the time limit for each request. Default value is 15 seconds
+func OptionTimeout(timeout time.Duration) Option {
+	return func(cc *checkConfig) {
+		if timeout > 0 {
+			cc.timeout = timeout
+		}
+	}
+}
+
+// Checker checks links in the background, each unique URL only once.
+// The links are queued and checked by the fixed number of workers, so scheduling them never blocks.
+// Links are requested with HEAD first, and with GET if the server doesn't handle HEAD properly
+type Checker struct {
+	ctx    context.Context
+	client *http.Client
+	ticker *time.Ticker
+	mut    sync.Mutex
+	cond   *sync.Cond
+	// queue holds the results of the links waiting for a worker
+	queue   []*Result
+	closed  bool
+	results map[string]*Result
+	wg      sync.WaitGroup
+}
+
+// NewChecker makes a new Checker and starts its workers. The checks stop when ctx is cancelled. Wait must be called to release its resources
+func NewChecker(ctx context.Context, options ...Option) *Checker {
+	config := checkConfig{
+		maxRoutines: defaultMaxRoutines,
+		rate:        defaultRate,
+		timeout:     defaultTimeout,
+	}
+	for _, o := range options {
+		o(&config)
+	}
+	c := &Checker{
+		ctx: ctx,
+		client: &http.Client{
+			Timeout: config.timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= defaultMaxRedirects {
+					return fmt.Errorf("HTTP client exceeded maximum of %d redirects", defaultMaxRedirects)
+				}
+				return nil
+			},
+		},
+		ticker:  time.NewTicker(config.interval()),
+		results: make(map[string]*Result),
+	}
+	c.cond = sync.NewCond(&c.mut)
+	c.wg.Add(int(config.maxRoutines))
+	for i := uint(0); i < config.maxRoutines; i++ {
+		go c.work()
+	}
+	return c
+}
+
+// Key returns the URL the link is checked by: fragments are dropped since they don't take part in the request
+func Key(u url.URL) string {
+	u.Fragment = ""
+	return u.String()
+}
+
+// Check schedules the link for checking unless it was already checked
+func (c *Checker) Check(u url.URL) {
+	addr := Key(u)
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if _, has := c.results[addr]; has {
+		return
+	}
+	res := &Result{URL: addr}
+	c.results[addr] = res
+	c.queue = append(c.queue, res)
+	c.cond.Signal()
+}
+
+// next waits for a queued link and takes it from the queue. ok is false when the queue is closed and empty, the worker should exit then
+func (c *Checker) next() (res *Result, ok bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for len(c.queue) == 0 && !c.closed {
+		c.cond.Wait()
+	}
+	if len(c.queue) == 0 {
+		return nil, false
+	}
+	res = c.queue[0]
+	// The taken result is cleared so the queue doesn't keep it in the underlying array
+	c.queue[0] = nil
+	c.queue = c.queue[1:]
+	return res, true
+}
+
+// work checks the queued links till the queue is closed. Once the context is cancelled the rest of links are only marked with its error
+func (c *Checker) work() {
+	defer c.wg.Done()
+	for {
+		res, ok := c.next()
+		if !ok {
+			return
+		}
+		if err := c.ctx.Err(); err != nil {
+			res.Error = err
+			continue
+		}
+		c.check(res)
+	}
+}
+
+func (c *Checker) check(res *Result) {
+	response, duration, err := c.request(http.MethodHead, res.URL)
+	if err != nil || response.StatusCode >= 400 {
+		// Plenty of servers answer HEAD requests with errors while serving the page fine
+		response, duration, err = c.request(http.MethodGet, res.URL)
+	}
+	res.Duration = duration
+	if err != nil {
+		res.Error = err
+		return
+	}
+	res.Status = response.StatusCode
+	if final := response.Request.URL.String(); final != res.URL {
+		res.RedirectURL = final
+	}
+}
+
+func (c *Checker) request(method, addr string) (*http.Response, time.Duration, error) {
+	select {
+	case <-c.ticker.C:
+	case <-c.ctx.Done():
+		return nil, 0, c.ctx.Err()
+	}
+	req, err := http.NewRequestWithContext(c.ctx, method, addr, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := time.Now()
+	response, err := c.client.Do(req)
+	if err != nil {
+		return nil, time.Since(start), err
+	}
+	response.Body.Close()
+	return response, time.Since(start), nil
+}
+
+// Wait blocks until all scheduled links are checked and returns the results by the checked URLs (see Key).
+// The links left unchecked because the context was cancelled are not in the results. Check must not be called after Wait
+func (c *Checker) Wait() map[string]Result {
+	c.mut.Lock()
+	c.closed = true
+	c.cond.Broadcast()
+	c.mut.Unlock()
+	c.wg.Wait()
+	c.ticker.Stop()
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	results := make(map[string]Result, len(c.results))
+	for addr, res := range c.results {
+		if err := c.ctx.Err(); err != nil && errors.Is(res.Error, err) {
+			continue
+		}
+		results[addr] = *res
+	}
+	return results
+}
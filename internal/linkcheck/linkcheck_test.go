@@ -0,0 +1,112 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer serves /ok and /new, redirects /moved to /new and answers HEAD requests of /nohead with errors. It counts the requests by path
+type testServer struct {
+	*httptest.Server
+	mut      sync.Mutex
+	requests map[string]int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	ts := &testServer{requests: make(map[string]int)}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.mut.Lock()
+		ts.requests[r.Method+" "+r.URL.Path]++
+		ts.mut.Unlock()
+		switch r.URL.Path {
+		case "/ok", "/new":
+		case "/moved":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (ts *testServer) url(t *testing.T, path string) url.URL {
+	t.Helper()
+	u, err := url.Parse(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *u
+}
+
+func TestChecker(t *testing.T) {
+	ts := newTestServer(t)
+	c := NewChecker(context.Background(), OptionRate(1000))
+	for _, path := range []string{"/ok", "/ok#top", "/moved", "/missing", "/nohead", "/ok"} {
+		c.Check(ts.url(t, path))
+	}
+	results := c.Wait()
+
+	tests := []struct {
+		path           string
+		wantStatus     int
+		wantDead       bool
+		wantRedirected bool
+	}{
+		{"/ok", http.StatusOK, false, false},
+		{"/moved", http.StatusOK, false, true},
+		{"/missing", http.StatusNotFound, true, false},
+		{"/nohead", http.StatusOK, false, false},
+	}
+	if len(results) != len(tests) {
+		t.Errorf("got %d results, want %d", len(results), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			res, ok := results[ts.URL+tt.path]
+			if !ok {
+				t.Fatal("the link is not checked")
+			}
+			if res.Status != tt.wantStatus || res.IsDead() != tt.wantDead || res.IsRedirected() != tt.wantRedirected {
+				t.Errorf("result is %+v", res)
+			}
+		})
+	}
+	if n := ts.requests["HEAD /ok"]; n != 1 {
+		t.Errorf("/ok is requested %d times with HEAD, want once", n)
+	}
+}
+
+func TestCheckerCancel(t *testing.T) {
+	ts := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewChecker(ctx, OptionMaxRoutines(1), OptionRate(1))
+	for i := 0; i < 100; i++ {
+		c.Check(ts.url(t, fmt.Sprintf("/ok?page=%d", i)))
+	}
+	cancel()
+
+	done := make(chan map[string]Result)
+	go func() {
+		done <- c.Wait()
+	}()
+	select {
+	case results := <-done:
+		if len(results) > 1 {
+			t.Errorf("got %d results after cancelling, want at most 1", len(results))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait doesn't return after cancelling")
+	}
+}
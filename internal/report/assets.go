@@ -0,0 +1,104 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/internal/linkcheck"
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// AssetIssue is a missing or slow asset of the page
+type AssetIssue struct {
+	Page   string
+	Kind   links.AssetKind
+	Result linkcheck.Result
+	// Slow tells that the asset loads but takes longer than allowed
+	Slow bool
+}
+
+func (issue AssetIssue) String() string {
+	switch {
+	case issue.Result.Error != nil:
+		return fmt.Sprintf("missing %s %s: %s", issue.Kind, issue.Result.URL, issue.Result.Error.Error())
+	case issue.Result.IsDead():
+		return fmt.Sprintf("missing %s %s: %d", issue.Kind, issue.Result.URL, issue.Result.Status)
+	}
+	return fmt.Sprintf("slow %s %s: %s", issue.Kind, issue.Result.URL, issue.Result.Duration.Round(time.Millisecond))
+}
+
+// AssetChecker checks the assets of crawled pages in the background as the results arrive. Each unique asset is requested once
+type AssetChecker struct {
+	checker *linkcheck.Checker
+	slow    time.Duration
+	assets  map[string][]links.Asset
+}
+
+// NewAssetChecker makes a new AssetChecker. Assets taking longer than slow to respond are reported as slow, 0 turns it off.
+// The checks stop when ctx is cancelled, the options configure their concurrency and rate
+func NewAssetChecker(ctx context.Context, slow time.Duration, options ...linkcheck.Option) *AssetChecker {
+	return &AssetChecker{
+		checker: linkcheck.NewChecker(ctx, options...),
+		slow:    slow,
+		assets:  make(map[string][]links.Asset),
+	}
+}
+
+// Add schedules the checks of the assets of the search result. Results must have assets collected with linkcrawler.OptionCollectAssets
+func (ac *AssetChecker) Add(res linkcrawler.SearchResult) {
+	if res.Error != nil || len(res.Assets) == 0 {
+		return
+	}
+	ac.assets[res.Addr] = append(ac.assets[res.Addr], res.Assets...)
+	for _, a := range res.Assets {
+		ac.checker.Check(a.URL)
+	}
+}
+
+// Issues waits for all checks to finish and returns missing and slow assets sorted by page and URL, along with the number of checked assets.
+// Add must not be called after Issues
+func (ac *AssetChecker) Issues() ([]AssetIssue, int) {
+	results := ac.checker.Wait()
+	issues := make([]AssetIssue, 0)
+	for page, assets := range ac.assets {
+		for _, a := range assets {
+			res := results[linkcheck.Key(a.URL)]
+			issue := AssetIssue{Page: page, Kind: a.Kind, Result: res}
+			if !res.IsDead() {
+				if ac.slow == 0 || res.Duration <= ac.slow {
+					continue
+				}
+				issue.Slow = true
+			}
+			issues = append(issues, issue)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Page != issues[j].Page {
+			return issues[i].Page < issues[j].Page
+		}
+		return issues[i].Result.URL < issues[j].Result.URL
+	})
+	return issues, len(results)
+}
+
+// WriteAssetReport writes the issues into w as text grouped by the page they are found on
+func WriteAssetReport(w io.Writer, issues []AssetIssue) error {
+	page := ""
+	for _, issue := range issues {
+		if issue.Page != page {
+			page = issue.Page
+			if _, err := fmt.Fprintln(w, page); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "    %s\n", issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
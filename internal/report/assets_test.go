@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/internal/linkcheck"
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+func TestAssetChecker(t *testing.T) {
+	server := newLinkServer(t)
+	asset := func(path string, kind links.AssetKind) links.Asset {
+		u, err := url.Parse(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return links.Asset{URL: *u, Kind: kind}
+	}
+	results := []linkcrawler.SearchResult{
+		{Addr: "https://example.com/", Assets: []links.Asset{
+			asset("/ok", links.AssetStylesheet),
+			asset("/slow", links.AssetImage),
+			asset("/missing", links.AssetScript),
+		}},
+		{Addr: "https://example.com/about", Assets: []links.Asset{
+			asset("/ok", links.AssetStylesheet),
+			asset("/missing", links.AssetScript),
+		}},
+	}
+	tests := []struct {
+		name string
+		slow time.Duration
+		want string
+	}{
+		{"missing", 0, `https://example.com/
+    missing script SERVER/missing: 404
+https://example.com/about
+    missing script SERVER/missing: 404
+`},
+		{"slow", 50 * time.Millisecond, `https://example.com/
+    missing script SERVER/missing: 404
+    slow image SERVER/slow: DURATION
+https://example.com/about
+    missing script SERVER/missing: 404
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac := NewAssetChecker(context.Background(), tt.slow, linkcheck.OptionRate(1000))
+			for _, res := range results {
+				ac.Add(res)
+			}
+			issues, checked := ac.Issues()
+			if checked != 3 {
+				t.Errorf("checked %d assets, want 3", checked)
+			}
+			for _, issue := range issues {
+				if issue.Slow != (issue.Result.URL == server.URL+"/slow") {
+					t.Errorf("issue %v is slow: %t", issue, issue.Slow)
+				}
+			}
+			buf := &bytes.Buffer{}
+			if err := WriteAssetReport(buf, issues); err != nil {
+				t.Fatalf("WriteAssetReport failed: %v", err)
+			}
+			got := strings.ReplaceAll(buf.String(), server.URL, "SERVER")
+			for _, issue := range issues {
+				if issue.Slow {
+					got = strings.Replace(got, issue.Result.Duration.Round(time.Millisecond).String(), "DURATION", 1)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("report is:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
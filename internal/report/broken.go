@@ -0,0 +1,128 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// Referrer is the page linking to the broken URL
+type Referrer struct {
+	Page string
+	// Anchor is the text of the link on the page
+	Anchor string
+}
+
+// BrokenLink is the link target that failed to load
+type BrokenLink struct {
+	URL string
+	// Status is the HTTP status code, it's 0 if the request failed without response
+	Status   int
+	Category linkcrawler.ErrorCategory
+	Error    error
+	// Referrers are all crawled pages linking to the URL
+	Referrers []Referrer
+}
+
+func (bl BrokenLink) String() string {
+	if bl.Status != 0 {
+		return fmt.Sprintf("%s: %d (%s)", bl.URL, bl.Status, bl.Category)
+	}
+	return fmt.Sprintf("%s: %s (%s)", bl.URL, bl.Error.Error(), bl.Category)
+}
+
+// BrokenLinkChecker accumulates the links of crawled pages and the failed ones to match them once the crawl is finished
+type BrokenLinkChecker struct {
+	referrers map[string][]Referrer
+	failures  map[string]linkcrawler.SearchResult
+}
+
+// NewBrokenLinkChecker makes a new BrokenLinkChecker
+func NewBrokenLinkChecker() *BrokenLinkChecker {
+	return &BrokenLinkChecker{
+		referrers: make(map[string][]Referrer),
+		failures:  make(map[string]linkcrawler.SearchResult),
+	}
+}
+
+// linkTarget identifies the linked page by its URL without the fragment
+func linkTarget(u url.URL) string {
+	u.Fragment = ""
+	return u.String()
+}
+
+// Add records the search result
+func (bc *BrokenLinkChecker) Add(res linkcrawler.SearchResult) {
+	if res.Error != nil {
+		var parseErr links.LinkParseError
+		if errors.As(res.Error, &parseErr) {
+			// Malformed hrefs are reported for the page they are found on, which itself is fine
+			return
+		}
+		if _, has := bc.failures[res.Addr]; !has {
+			bc.failures[res.Addr] = res
+		}
+		return
+	}
+	for _, link := range res.Links {
+		target := linkTarget(link.URL)
+		bc.referrers[target] = append(bc.referrers[target], Referrer{Page: res.Addr, Anchor: link.Name})
+	}
+}
+
+// BrokenLinks returns all failed URLs with the pages linking to them, sorted by URL
+func (bc *BrokenLinkChecker) BrokenLinks() []BrokenLink {
+	broken := make([]BrokenLink, 0, len(bc.failures))
+	for addr, res := range bc.failures {
+		bl := BrokenLink{
+			URL:       addr,
+			Status:    res.Status,
+			Category:  res.Category,
+			Error:     res.Error,
+			Referrers: make([]Referrer, 0),
+		}
+		seen := make(map[Referrer]bool)
+		for _, r := range bc.referrers[addr] {
+			if !seen[r] {
+				seen[r] = true
+				bl.Referrers = append(bl.Referrers, r)
+			}
+		}
+		sort.Slice(bl.Referrers, func(i, j int) bool {
+			if bl.Referrers[i].Page != bl.Referrers[j].Page {
+				return bl.Referrers[i].Page < bl.Referrers[j].Page
+			}
+			return bl.Referrers[i].Anchor < bl.Referrers[j].Anchor
+		})
+		broken = append(broken, bl)
+	}
+	sort.Slice(broken, func(i, j int) bool {
+		return broken[i].URL < broken[j].URL
+	})
+	return broken
+}
+
+// WriteBrokenLinkReport writes the broken links into w as text, each followed by the list of pages linking to it
+func WriteBrokenLinkReport(w io.Writer, broken []BrokenLink) error {
+	for _, bl := range broken {
+		if _, err := fmt.Fprintln(w, bl); err != nil {
+			return err
+		}
+		if bl.Status != 0 {
+			if _, err := fmt.Fprintf(w, "    %s\n", bl.Error.Error()); err != nil {
+				return err
+			}
+		}
+		for _, r := range bl.Referrers {
+			if _, err := fmt.Fprintf(w, "    linked from %s with %q\n", r.Page, r.Anchor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
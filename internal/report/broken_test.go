@@ -0,0 +1,91 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// testLink returns the link with given anchor text
+func testLink(t *testing.T, name, href string) links.Link {
+	t.Helper()
+	u, err := url.Parse(href)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return links.Link{Name: name, URL: *u}
+}
+
+// failedPage returns the search result of the page that failed with given status
+func failedPage(addr string, status int, category linkcrawler.ErrorCategory) linkcrawler.SearchResult {
+	return linkcrawler.SearchResult{
+		Addr:     addr,
+		Status:   status,
+		Category: category,
+		Error:    &linkcrawler.CrawlError{URL: addr, Category: category, Err: errors.New(string(category))},
+	}
+}
+
+func TestBrokenLinkChecker(t *testing.T) {
+	const home, about, missing = "https://example.com/", "https://example.com/about", "https://example.com/missing"
+	bc := NewBrokenLinkChecker()
+	bc.Add(linkcrawler.SearchResult{Addr: home, Status: 200, Links: []links.Link{
+		testLink(t, "Missing", missing),
+		testLink(t, "Missing", missing+"#part"),
+		testLink(t, "About", about),
+	}})
+	bc.Add(linkcrawler.SearchResult{Addr: about, Status: 200, Links: []links.Link{
+		testLink(t, "Gone", missing),
+		testLink(t, "Down", "https://example.com/down"),
+	}})
+	bc.Add(failedPage(missing, 404, linkcrawler.CategoryClientError))
+	bc.Add(failedPage("https://example.com/down", 0, linkcrawler.CategoryConnectionRefused))
+	// Parse errors are reported for the pages the malformed links are found on, the pages themselves are fine
+	bc.Add(linkcrawler.SearchResult{
+		Addr:     about,
+		Status:   200,
+		Category: linkcrawler.CategoryParse,
+		Error:    &linkcrawler.CrawlError{URL: about, Category: linkcrawler.CategoryParse, Err: links.LinkParseError{Href: "%zz"}},
+	})
+
+	broken := bc.BrokenLinks()
+	if len(broken) != 2 {
+		t.Fatalf("got broken links %v, want 2", broken)
+	}
+	down, notFound := broken[0], broken[1]
+	if down.URL != "https://example.com/down" || down.Status != 0 || len(down.Referrers) != 1 || down.Referrers[0] != (Referrer{Page: about, Anchor: "Down"}) {
+		t.Errorf("first broken link is %+v", down)
+	}
+	// The link with fragment leads to the same page, so it counts once
+	wantReferrers := []Referrer{{Page: home, Anchor: "Missing"}, {Page: about, Anchor: "Gone"}}
+	if notFound.URL != missing || notFound.Status != 404 || notFound.Category != linkcrawler.CategoryClientError {
+		t.Errorf("second broken link is %+v", notFound)
+	}
+	if len(notFound.Referrers) != len(wantReferrers) {
+		t.Fatalf("%s is linked from %v, want %v", missing, notFound.Referrers, wantReferrers)
+	}
+	for i, r := range notFound.Referrers {
+		if r != wantReferrers[i] {
+			t.Errorf("referrer %d is %+v, want %+v", i, r, wantReferrers[i])
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteBrokenLinkReport(buf, broken); err != nil {
+		t.Fatalf("WriteBrokenLinkReport failed: %v", err)
+	}
+	want := `https://example.com/down: connection refused (connection refused)
+    linked from https://example.com/about with "Down"
+https://example.com/missing: 404 (client error)
+    client error
+    linked from https://example.com/ with "Missing"
+    linked from https://example.com/about with "Gone"
+`
+	if buf.String() != want {
+		t.Errorf("report is:\n%s\nwant:\n%s", buf, want)
+	}
+}
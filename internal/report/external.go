@@ -0,0 +1,102 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/TofuOverdose/WebMapMaker/internal/linkcheck"
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// ExternalLinkIssue is a dead or redirected outbound link of the page
+type ExternalLinkIssue struct {
+	Page   string
+	Anchor string
+	Result linkcheck.Result
+}
+
+func (issue ExternalLinkIssue) String() string {
+	var msg string
+	switch {
+	case issue.Result.Error != nil:
+		msg = fmt.Sprintf("dead %s: %s", issue.Result.URL, issue.Result.Error.Error())
+	case issue.Result.IsDead():
+		msg = fmt.Sprintf("dead %s: %d", issue.Result.URL, issue.Result.Status)
+	default:
+		msg = fmt.Sprintf("redirected %s -> %s", issue.Result.URL, issue.Result.RedirectURL)
+	}
+	return fmt.Sprintf("%s (linked with %q)", msg, issue.Anchor)
+}
+
+// ExternalLinkChecker checks the outbound links of crawled pages in the background as the results arrive
+type ExternalLinkChecker struct {
+	checker *linkcheck.Checker
+	links   map[string][]links.Link
+}
+
+// NewExternalLinkChecker makes a new ExternalLinkChecker. The checks stop when ctx is cancelled, the options configure their concurrency and rate
+func NewExternalLinkChecker(ctx context.Context, options ...linkcheck.Option) *ExternalLinkChecker {
+	return &ExternalLinkChecker{
+		checker: linkcheck.NewChecker(ctx, options...),
+		links:   make(map[string][]links.Link),
+	}
+}
+
+// Add schedules the checks of the outbound links of the search result
+func (ec *ExternalLinkChecker) Add(res linkcrawler.SearchResult) {
+	if res.Error != nil || len(res.OutboundLinks) == 0 {
+		return
+	}
+	ec.links[res.Addr] = append(ec.links[res.Addr], res.OutboundLinks...)
+	for _, link := range res.OutboundLinks {
+		ec.checker.Check(link.URL)
+	}
+}
+
+// Issues waits for all checks to finish and returns dead and redirected links sorted by page and URL, along with the number of checked links.
+// Add must not be called after Issues
+func (ec *ExternalLinkChecker) Issues() ([]ExternalLinkIssue, int) {
+	results := ec.checker.Wait()
+	issues := make([]ExternalLinkIssue, 0)
+	for page, pageLinks := range ec.links {
+		seen := make(map[string]bool)
+		for _, link := range pageLinks {
+			res := results[linkcheck.Key(link.URL)]
+			if seen[res.URL+" "+link.Name] || !res.IsDead() && !res.IsRedirected() {
+				continue
+			}
+			seen[res.URL+" "+link.Name] = true
+			issues = append(issues, ExternalLinkIssue{Page: page, Anchor: link.Name, Result: res})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Page != issues[j].Page {
+			return issues[i].Page < issues[j].Page
+		}
+		if issues[i].Result.URL != issues[j].Result.URL {
+			return issues[i].Result.URL < issues[j].Result.URL
+		}
+		return issues[i].Anchor < issues[j].Anchor
+	})
+	return issues, len(results)
+}
+
+// WriteExternalLinkReport writes the issues into w as text grouped by the page they are found on
+func WriteExternalLinkReport(w io.Writer, issues []ExternalLinkIssue) error {
+	page := ""
+	for _, issue := range issues {
+		if issue.Page != page {
+			page = issue.Page
+			if _, err := fmt.Fprintln(w, page); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "    %s\n", issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
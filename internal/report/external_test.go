@@ -0,0 +1,69 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/internal/linkcheck"
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// newLinkServer serves /ok, redirects /moved to /ok and makes /slow respond in 100 milliseconds, the rest of paths are not found
+func newLinkServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExternalLinkChecker(t *testing.T) {
+	server := newLinkServer(t)
+	const home, about = "https://example.com/", "https://example.com/about"
+	ec := NewExternalLinkChecker(context.Background(), linkcheck.OptionRate(1000))
+	ec.Add(linkcrawler.SearchResult{Addr: home, OutboundLinks: []links.Link{
+		testLink(t, "Partner", server.URL+"/ok"),
+		testLink(t, "Old", server.URL+"/moved"),
+		testLink(t, "Old", server.URL+"/moved#section"),
+	}})
+	ec.Add(linkcrawler.SearchResult{Addr: about, OutboundLinks: []links.Link{
+		testLink(t, "Partner", server.URL+"/ok"),
+		testLink(t, "Dead", server.URL+"/missing"),
+	}})
+	// The outbound links of failed pages are not checked
+	failed := failedPage("https://example.com/error", 500, linkcrawler.CategoryServerError)
+	failed.OutboundLinks = []links.Link{testLink(t, "Other", server.URL+"/other")}
+	ec.Add(failed)
+
+	issues, checked := ec.Issues()
+	if checked != 3 {
+		t.Errorf("checked %d links, want 3", checked)
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteExternalLinkReport(buf, issues); err != nil {
+		t.Fatalf("WriteExternalLinkReport failed: %v", err)
+	}
+	want := `https://example.com/
+    redirected SERVER/moved -> SERVER/ok (linked with "Old")
+https://example.com/about
+    dead SERVER/missing: 404 (linked with "Dead")
+`
+	if got := strings.ReplaceAll(buf.String(), server.URL, "SERVER"); got != want {
+		t.Errorf("report is:\n%s\nwant:\n%s", got, want)
+	}
+}
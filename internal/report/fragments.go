@@ -0,0 +1,103 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+)
+
+// FragmentIssue is the link pointing to the element that doesn't exist on the target page
+type FragmentIssue struct {
+	Page   string
+	Anchor string
+	// Target is the URL of the linked page without the fragment
+	Target   string
+	Fragment string
+}
+
+func (issue FragmentIssue) String() string {
+	return fmt.Sprintf("%s: link %q to %s#%s: no element with id or name %q", issue.Page, issue.Anchor, issue.Target, issue.Fragment, issue.Fragment)
+}
+
+type fragmentLink struct {
+	page     string
+	anchor   string
+	fragment string
+}
+
+// FragmentChecker accumulates the anchors of crawled pages and the links with fragments to match them once the crawl is finished
+type FragmentChecker struct {
+	anchors map[string]map[string]bool
+	links   map[string][]fragmentLink
+}
+
+// NewFragmentChecker makes a new FragmentChecker
+func NewFragmentChecker() *FragmentChecker {
+	return &FragmentChecker{
+		anchors: make(map[string]map[string]bool),
+		links:   make(map[string][]fragmentLink),
+	}
+}
+
+// Add records the search result. Results must have anchors collected with linkcrawler.OptionCollectAnchors
+func (fc *FragmentChecker) Add(res linkcrawler.SearchResult) {
+	if res.Error != nil {
+		return
+	}
+	anchors := make(map[string]bool, len(res.Anchors))
+	for _, a := range res.Anchors {
+		anchors[a] = true
+	}
+	fc.anchors[res.Addr] = anchors
+
+	for _, link := range res.Links {
+		if link.URL.Fragment == "" {
+			continue
+		}
+		target := linkTarget(link.URL)
+		fc.links[target] = append(fc.links[target], fragmentLink{page: res.Addr, anchor: link.Name, fragment: link.URL.Fragment})
+	}
+}
+
+// Issues returns the links whose fragments don't match any element of the target page, sorted by page.
+// Links to the pages that were not crawled can't be checked and are left out
+func (fc *FragmentChecker) Issues() []FragmentIssue {
+	issues := make([]FragmentIssue, 0)
+	for target, links := range fc.links {
+		anchors, crawled := fc.anchors[target]
+		if !crawled {
+			continue
+		}
+		seen := make(map[fragmentLink]bool)
+		for _, link := range links {
+			// Browsers scroll to the top for "#top" even if there is no such element
+			if anchors[link.fragment] || link.fragment == "top" || seen[link] {
+				continue
+			}
+			seen[link] = true
+			issues = append(issues, FragmentIssue{Page: link.page, Anchor: link.anchor, Target: target, Fragment: link.fragment})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Page != issues[j].Page {
+			return issues[i].Page < issues[j].Page
+		}
+		if issues[i].Target != issues[j].Target {
+			return issues[i].Target < issues[j].Target
+		}
+		return issues[i].Fragment < issues[j].Fragment
+	})
+	return issues
+}
+
+// WriteFragmentReport writes the issues into w as text, one issue per line
+func WriteFragmentReport(w io.Writer, issues []FragmentIssue) error {
+	for _, issue := range issues {
+		if _, err := fmt.Fprintln(w, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+func TestFragmentChecker(t *testing.T) {
+	const home, about = "https://example.com/", "https://example.com/about"
+	fc := NewFragmentChecker()
+	fc.Add(linkcrawler.SearchResult{Addr: home, Anchors: []string{"intro", "legacy"}, Links: []links.Link{
+		testLink(t, "Intro", home+"#intro"),
+		testLink(t, "Legacy", home+"#legacy"),
+		testLink(t, "Top", home+"#top"),
+		testLink(t, "Team", about+"#team"),
+		testLink(t, "Team", about+"#team"),
+		testLink(t, "Jobs", about+"#jobs"),
+		testLink(t, "Elsewhere", "https://example.org/#missing"),
+	}})
+	fc.Add(linkcrawler.SearchResult{Addr: about, Anchors: []string{"team"}, Links: []links.Link{
+		testLink(t, "Outro", home+"#outro"),
+		testLink(t, "Home", home),
+	}})
+	// The anchors of failed pages are unknown, so the links to them are not checked
+	fc.Add(failedPage("https://example.com/error", 500, linkcrawler.CategoryServerError))
+	fc.Add(linkcrawler.SearchResult{Addr: about + "/more", Links: []links.Link{
+		testLink(t, "Error", "https://example.com/error#part"),
+	}})
+
+	buf := &bytes.Buffer{}
+	if err := WriteFragmentReport(buf, fc.Issues()); err != nil {
+		t.Fatalf("WriteFragmentReport failed: %v", err)
+	}
+	want := `https://example.com/: link "Jobs" to https://example.com/about#jobs: no element with id or name "jobs"
+https://example.com/about: link "Outro" to https://example.com/#outro: no element with id or name "outro"
+`
+	if buf.String() != want {
+		t.Errorf("report is:\n%s\nwant:\n%s", buf, want)
+	}
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+	"github.com/TofuOverdose/WebMapMaker/sitemap"
+)
+
+// HreflangProblem is a kind of problem with language alternates
+type HreflangProblem string
+
+const (
+	// HreflangInvalidLang means that hreflang value is not a valid language code
+	HreflangInvalidLang HreflangProblem = "invalid language code"
+	// HreflangNoReciprocal means that the alternate page doesn't link back to the page referencing it
+	HreflangNoReciprocal HreflangProblem = "missing reciprocal link"
+	// HreflangFailed means that the alternate page failed to load
+	HreflangFailed HreflangProblem = "alternate returns error"
+	// HreflangOutOfCrawl means that the alternate page was not reached by crawler
+	HreflangOutOfCrawl HreflangProblem = "alternate is outside of the crawl"
+)
+
+// HreflangIssue describes a single problem with the language alternate listed by the page
+type HreflangIssue struct {
+	Page      string
+	Lang      string
+	Alternate string
+	Problem   HreflangProblem
+	// Details tells more about the problem, like the error returned by alternate page
+	Details string
+}
+
+func (issue HreflangIssue) String() string {
+	msg := fmt.Sprintf("%s: [%s] %s: %s", issue.Page, issue.Lang, issue.Alternate, issue.Problem)
+	if issue.Details != "" {
+		msg += " (" + issue.Details + ")"
+	}
+	return msg
+}
+
+// HreflangChecker accumulates the language alternates of crawled pages to check the clusters they form once the crawl is finished
+type HreflangChecker struct {
+	alternates map[string]map[string]string
+	errors     map[string]error
+}
+
+// NewHreflangChecker makes a new HreflangChecker
+func NewHreflangChecker() *HreflangChecker {
+	return &HreflangChecker{
+		alternates: make(map[string]map[string]string),
+		errors:     make(map[string]error),
+	}
+}
+
+// Add records the search result. Results must have alternates collected with linkcrawler.OptionCollectAlternates
+func (hc *HreflangChecker) Add(res linkcrawler.SearchResult) {
+	if res.Error != nil {
+		var parseErr links.LinkParseError
+		if errors.As(res.Error, &parseErr) {
+			// Malformed hrefs are reported for the page they are found on, which itself is fine
+			return
+		}
+		if _, has := hc.errors[res.Addr]; !has {
+			hc.errors[res.Addr] = res.Error
+		}
+		return
+	}
+	alternates := make(map[string]string)
+	for _, a := range res.Alternates {
+		alternates[a.URL.String()] = a.Lang
+	}
+	hc.alternates[res.Addr] = alternates
+}
+
+// Issues checks the recorded alternates and returns all found problems sorted by page
+func (hc *HreflangChecker) Issues() []HreflangIssue {
+	issues := make([]HreflangIssue, 0)
+	for page, alternates := range hc.alternates {
+		for alt, lang := range alternates {
+			issue := HreflangIssue{Page: page, Lang: lang, Alternate: alt}
+			if !sitemap.IsValidHreflang(lang) {
+				issue.Problem = HreflangInvalidLang
+				issues = append(issues, issue)
+			}
+			if alt == page {
+				continue
+			}
+			if err, failed := hc.errors[alt]; failed {
+				issue.Problem = HreflangFailed
+				issue.Details = err.Error()
+				issues = append(issues, issue)
+				continue
+			}
+			altAlternates, crawled := hc.alternates[alt]
+			if !crawled {
+				issue.Problem = HreflangOutOfCrawl
+				issues = append(issues, issue)
+				continue
+			}
+			if _, linksBack := altAlternates[page]; !linksBack {
+				issue.Problem = HreflangNoReciprocal
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Page != issues[j].Page {
+			return issues[i].Page < issues[j].Page
+		}
+		if issues[i].Alternate != issues[j].Alternate {
+			return issues[i].Alternate < issues[j].Alternate
+		}
+		return issues[i].Problem < issues[j].Problem
+	})
+	return issues
+}
+
+// WriteHreflangReport writes the issues into w as text, one issue per line
+func WriteHreflangReport(w io.Writer, issues []HreflangIssue) error {
+	for _, issue := range issues {
+		if _, err := fmt.Fprintln(w, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/TofuOverdose/WebMapMaker/linkcrawler"
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// page returns the successful search result of the page with the given alternates of form lang=URL
+func page(t *testing.T, addr string, alternates ...string) linkcrawler.SearchResult {
+	t.Helper()
+	res := linkcrawler.SearchResult{Addr: addr, Status: 200}
+	for _, alt := range alternates {
+		parts := strings.SplitN(alt, "=", 2)
+		u, err := url.Parse(parts[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Alternates = append(res.Alternates, links.Alternate{Lang: parts[0], URL: *u})
+	}
+	return res
+}
+
+func TestHreflangChecker(t *testing.T) {
+	const en, de = "https://example.com/", "https://example.com/de/"
+	failed := linkcrawler.SearchResult{
+		Addr:     de,
+		Status:   404,
+		Category: linkcrawler.CategoryClientError,
+		Error:    &linkcrawler.CrawlError{URL: de, Category: linkcrawler.CategoryClientError, Err: errors.New("Not Found")},
+	}
+	// The crawler reports malformed hrefs of the page as separate results with its address and status
+	parseFailed := linkcrawler.SearchResult{
+		Addr:     de,
+		Status:   200,
+		Category: linkcrawler.CategoryParse,
+		Error:    &linkcrawler.CrawlError{URL: de, Category: linkcrawler.CategoryParse, Err: links.LinkParseError{Href: "%zz"}},
+	}
+	tests := []struct {
+		name    string
+		results []linkcrawler.SearchResult
+		want    []HreflangProblem
+	}{
+		{"reciprocal", []linkcrawler.SearchResult{
+			page(t, en, "en="+en, "de="+de),
+			page(t, de, "en="+en, "de="+de),
+		}, nil},
+		{"no reciprocal", []linkcrawler.SearchResult{
+			page(t, en, "en="+en, "de="+de),
+			page(t, de),
+		}, []HreflangProblem{HreflangNoReciprocal}},
+		{"invalid language", []linkcrawler.SearchResult{
+			page(t, en, "english="+en),
+		}, []HreflangProblem{HreflangInvalidLang}},
+		{"out of crawl", []linkcrawler.SearchResult{
+			page(t, en, "de="+de),
+		}, []HreflangProblem{HreflangOutOfCrawl}},
+		{"failed alternate", []linkcrawler.SearchResult{
+			page(t, en, "de="+de),
+			failed,
+		}, []HreflangProblem{HreflangFailed}},
+		{"link parse error of alternate", []linkcrawler.SearchResult{
+			page(t, en, "en="+en, "de="+de),
+			parseFailed,
+			page(t, de, "en="+en, "de="+de),
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHreflangChecker()
+			for _, res := range tt.results {
+				hc.Add(res)
+			}
+			issues := hc.Issues()
+			if len(issues) != len(tt.want) {
+				t.Fatalf("got issues %v, want %v", issues, tt.want)
+			}
+			for i, issue := range issues {
+				if issue.Problem != tt.want[i] {
+					t.Errorf("issue %q, want %q", issue, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package sitetree
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+const defaultPageTitle = "Site map"
+
+type renderConfig struct {
+	maxDepth int
+	sortBy   SortBy
+	title    string
+}
+
+// RenderOption configures how the tree is rendered
+type RenderOption func(*renderConfig)
+
+// RenderOptionMaxDepth collapses the nodes deeper than depth levels below the root, so only the number of pages under them is shown.
+// Default value is 0, which means that the whole tree is rendered
+func RenderOptionMaxDepth(depth int) RenderOption {
+	return func(rc *renderConfig) {
+		rc.maxDepth = depth
+	}
+}
+
+// RenderOptionSort sets the order of nodes on each level. Default value is SortPath
+func RenderOptionSort(by SortBy) RenderOption {
+	return func(rc *renderConfig) {
+		rc.sortBy = by
+	}
+}
+
+// RenderOptionTitle sets the heading of HTML and Markdown output. Default value is "Site map"
+func RenderOptionTitle(title string) RenderOption {
+	return func(rc *renderConfig) {
+		rc.title = title
+	}
+}
+
+// view is the node prepared for rendering according to the options
+type view struct {
+	segment  string
+	url      string
+	title    string
+	children []*view
+	// collapsed is the number of pages left out of rendering by depth limit
+	collapsed int
+}
+
+func (v *view) label() string {
+	if v.title != "" {
+		return v.title
+	}
+	return v.segment
+}
+
+// prepareView sorts the children of the node, merges chains of segments that are not pages into a single node
+// like "docs/v1" and collapses the nodes deeper than the limit
+func prepareView(n *Node, depth int, config renderConfig) *view {
+	v := &view{segment: n.Segment, url: n.URL, title: n.Title}
+	for !n.IsPage() && len(n.Children) == 1 && depth > 0 {
+		n = n.Children[0]
+		v.segment += "/" + n.Segment
+		v.url = n.URL
+		v.title = n.Title
+	}
+	if config.maxDepth > 0 && depth >= config.maxDepth {
+		for _, c := range n.Children {
+			v.collapsed += c.PageCount()
+		}
+		return v
+	}
+	for _, c := range sortNodes(n.Children, config.sortBy) {
+		v.children = append(v.children, prepareView(c, depth+1, config))
+	}
+	return v
+}
+
+func (t *Tree) views(options []RenderOption) ([]*view, renderConfig) {
+	config := renderConfig{sortBy: SortPath, title: defaultPageTitle}
+	for _, o := range options {
+		o(&config)
+	}
+	views := make([]*view, 0, len(t.Roots))
+	for _, r := range sortNodes(t.Roots, SortPath) {
+		views = append(views, prepareView(r, 0, config))
+	}
+	return views, config
+}
+
+func collapsedText(count int) string {
+	if count == 1 {
+		return "1 more page"
+	}
+	return fmt.Sprintf("%d more pages", count)
+}
+
+// WriteASCII writes the tree into w drawn with box-drawing characters, which is suitable for terminal
+func (t *Tree) WriteASCII(w io.Writer, options ...RenderOption) error {
+	views, _ := t.views(options)
+	bw := bufio.NewWriter(w)
+	var draw func(v *view, prefix string)
+	draw = func(v *view, prefix string) {
+		lines := len(v.children)
+		if v.collapsed > 0 {
+			lines++
+		}
+		for i, c := range v.children {
+			branch, next := "├── ", "│   "
+			if i == lines-1 {
+				branch, next = "└── ", "    "
+			}
+			fmt.Fprintf(bw, "%s%s%s\n", prefix, branch, asciiLabel(c))
+			draw(c, prefix+next)
+		}
+		if v.collapsed > 0 {
+			fmt.Fprintf(bw, "%s└── … %s\n", prefix, collapsedText(v.collapsed))
+		}
+	}
+	for _, v := range views {
+		fmt.Fprintln(bw, asciiLabel(v))
+		draw(v, "")
+	}
+	return bw.Flush()
+}
+
+func asciiLabel(v *view) string {
+	if v.title != "" {
+		return fmt.Sprintf("%s (%s)", v.segment, v.title)
+	}
+	return v.segment
+}
+
+// WriteMarkdown writes the tree into w as Markdown nested list of links headed with the title
+func (t *Tree) WriteMarkdown(w io.Writer, options ...RenderOption) error {
+	views, config := t.views(options)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", escapeMarkdown(config.title))
+	var list func(v *view, indent string)
+	list = func(v *view, indent string) {
+		label := escapeMarkdown(v.label())
+		if v.url != "" {
+			label = fmt.Sprintf("[%s](<%s>)", label, v.url)
+		}
+		fmt.Fprintf(bw, "%s- %s\n", indent, label)
+		for _, c := range v.children {
+			list(c, indent+"  ")
+		}
+		if v.collapsed > 0 {
+			fmt.Fprintf(bw, "%s  - *%s*\n", indent, collapsedText(v.collapsed))
+		}
+	}
+	for _, v := range views {
+		list(v, "")
+	}
+	return bw.Flush()
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`", "<", `\<`)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// WriteHTML writes the tree into w as standalone HTML page with nested lists of links
+func (t *Tree) WriteHTML(w io.Writer, options ...RenderOption) error {
+	views, config := t.views(options)
+	bw := bufio.NewWriter(w)
+	title := html.EscapeString(config.title)
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n", title, title)
+	var list func(views []*view, collapsed int, indent string)
+	list = func(views []*view, collapsed int, indent string) {
+		fmt.Fprintf(bw, "%s<ul>\n", indent)
+		for _, v := range views {
+			label := html.EscapeString(v.label())
+			if v.url != "" {
+				label = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(v.url), label)
+			}
+			if len(v.children) == 0 && v.collapsed == 0 {
+				fmt.Fprintf(bw, "%s  <li>%s</li>\n", indent, label)
+				continue
+			}
+			fmt.Fprintf(bw, "%s  <li>%s\n", indent, label)
+			list(v.children, v.collapsed, indent+"    ")
+			fmt.Fprintf(bw, "%s  </li>\n", indent)
+		}
+		if collapsed > 0 {
+			fmt.Fprintf(bw, "%s  <li><em>%s</em></li>\n", indent, collapsedText(collapsed))
+		}
+		fmt.Fprintf(bw, "%s</ul>\n", indent)
+	}
+	list(views, 0, "")
+	fmt.Fprint(bw, "</body>\n</html>\n")
+	return bw.Flush()
+}
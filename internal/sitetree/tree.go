@@ -0,0 +1,149 @@
+package sitetree
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Node is a path segment of the site tree. Nodes for the segments that were never crawled as pages have empty URL
+type Node struct {
+	// Segment is the path segment of the node, or scheme and host for the root nodes
+	Segment string
+	// URL is the address of the page the node stands for
+	URL string
+	// Title is the title of the page, it's empty if the page has no title
+	Title    string
+	Children []*Node
+	children map[string]*Node
+}
+
+func newNode(segment string) *Node {
+	return &Node{
+		Segment:  segment,
+		children: make(map[string]*Node),
+	}
+}
+
+func (n *Node) child(segment string) *Node {
+	c, ok := n.children[segment]
+	if !ok {
+		c = newNode(segment)
+		n.children[segment] = c
+		n.Children = append(n.Children, c)
+	}
+	return c
+}
+
+// IsPage tells if the node stands for the crawled page
+func (n *Node) IsPage() bool {
+	return n.URL != ""
+}
+
+// Label is the text the node is shown with: the page title if it's known, otherwise the path segment
+func (n *Node) Label() string {
+	if n.Title != "" {
+		return n.Title
+	}
+	return n.Segment
+}
+
+// PageCount returns the number of pages in the subtree of the node including itself
+func (n *Node) PageCount() int {
+	count := 0
+	if n.IsPage() {
+		count++
+	}
+	for _, c := range n.Children {
+		count += c.PageCount()
+	}
+	return count
+}
+
+// Tree arranges the crawled URLs into hierarchy by their path segments. Every host gets its own root node
+type Tree struct {
+	Roots []*Node
+	roots map[string]*Node
+}
+
+// NewTree makes an empty Tree
+func NewTree() *Tree {
+	return &Tree{
+		roots: make(map[string]*Node),
+	}
+}
+
+// Add puts the page into the tree. title might be empty if it's unknown
+func (t *Tree) Add(addr, title string) error {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return err
+	}
+	host := u.Scheme + "://" + u.Host
+	node, ok := t.roots[host]
+	if !ok {
+		node = newNode(host)
+		t.roots[host] = node
+		t.Roots = append(t.Roots, node)
+	}
+
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	if u.RawQuery != "" {
+		segments[len(segments)-1] += "?" + u.RawQuery
+	}
+	for _, s := range segments {
+		if s == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(s); err == nil {
+			s = unescaped
+		}
+		node = node.child(s)
+	}
+	node.URL = addr
+	node.Title = strings.TrimSpace(title)
+	return nil
+}
+
+// SortBy is the order the children of every node are rendered in
+type SortBy string
+
+const (
+	// SortPath orders nodes by path segment alphabetically
+	SortPath SortBy = "path"
+	// SortTitle orders nodes by their labels alphabetically
+	SortTitle SortBy = "title"
+	// SortSize puts nodes with the most pages in their subtrees first
+	SortSize SortBy = "size"
+)
+
+// SortOrders lists all supported sort orders
+var SortOrders = []SortBy{SortPath, SortTitle, SortSize}
+
+func sortNodes(nodes []*Node, by SortBy) []*Node {
+	sorted := make([]*Node, len(nodes))
+	copy(sorted, nodes)
+	less := func(i, j int) bool {
+		return sorted[i].Segment < sorted[j].Segment
+	}
+	switch by {
+	case SortTitle:
+		less = func(i, j int) bool {
+			li, lj := strings.ToLower(sorted[i].Label()), strings.ToLower(sorted[j].Label())
+			if li != lj {
+				return li < lj
+			}
+			return sorted[i].Segment < sorted[j].Segment
+		}
+	case SortSize:
+		less = func(i, j int) bool {
+			ci, cj := sorted[i].PageCount(), sorted[j].PageCount()
+			if ci != cj {
+				return ci > cj
+			}
+			return sorted[i].Segment < sorted[j].Segment
+		}
+	}
+	sort.SliceStable(sorted, less)
+	return sorted
+}
@@ -0,0 +1,117 @@
+package sitetree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testTree(t *testing.T) *Tree {
+	t.Helper()
+	tree := NewTree()
+	pages := []struct{ addr, title string }{
+		{"https://example.com/", "Home"},
+		{"https://example.com/docs/v1/setup", ""},
+		{"https://example.com/docs/v1/intro", " Intro "},
+		{"https://example.com/blog", "Blog"},
+		{"https://example.com/blog/b-post", "Alpha"},
+		{"https://example.com/blog/a-post", "Zeta"},
+		{"https://example.com/blog/a%20post", ""},
+		{"https://other.com/search?q=1", ""},
+		{"https://example.com/about", "About"},
+	}
+	for _, p := range pages {
+		if err := tree.Add(p.addr, p.title); err != nil {
+			t.Fatalf("Add(%s) failed: %v", p.addr, err)
+		}
+	}
+	return tree
+}
+
+func TestTreeAdd(t *testing.T) {
+	tree := testTree(t)
+	if len(tree.Roots) != 2 {
+		t.Fatalf("tree has %d roots, want 2", len(tree.Roots))
+	}
+	root := tree.Roots[0]
+	if root.Segment != "https://example.com" || root.URL != "https://example.com/" || root.Label() != "Home" {
+		t.Errorf("root is %+v", root)
+	}
+	if count := root.PageCount(); count != 8 {
+		t.Errorf("root has %d pages, want 8", count)
+	}
+	docs := root.Children[0]
+	if docs.IsPage() || docs.Label() != "docs" || docs.PageCount() != 2 {
+		t.Errorf("docs node is %+v", docs)
+	}
+	if search := tree.Roots[1].Children[0]; search.Segment != "search?q=1" {
+		t.Errorf("query is kept as %q", search.Segment)
+	}
+	if err := tree.Add("%zz", ""); err == nil {
+		t.Error("Add of malformed URL succeeded")
+	}
+}
+
+func TestWriteASCII(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []RenderOption
+		want    string
+	}{
+		{"path", nil, `https://example.com (Home)
+├── about (About)
+├── blog (Blog)
+│   ├── a post
+│   ├── a-post (Zeta)
+│   └── b-post (Alpha)
+└── docs/v1
+    ├── intro (Intro)
+    └── setup
+https://other.com
+└── search?q=1
+`},
+		{"title", []RenderOption{RenderOptionSort(SortTitle)}, `https://example.com (Home)
+├── about (About)
+├── blog (Blog)
+│   ├── a post
+│   ├── b-post (Alpha)
+│   └── a-post (Zeta)
+└── docs/v1
+    ├── intro (Intro)
+    └── setup
+https://other.com
+└── search?q=1
+`},
+		{"size", []RenderOption{RenderOptionSort(SortSize)}, `https://example.com (Home)
+├── blog (Blog)
+│   ├── a post
+│   ├── a-post (Zeta)
+│   └── b-post (Alpha)
+├── docs/v1
+│   ├── intro (Intro)
+│   └── setup
+└── about (About)
+https://other.com
+└── search?q=1
+`},
+		{"depth", []RenderOption{RenderOptionMaxDepth(1)}, `https://example.com (Home)
+├── about (About)
+├── blog (Blog)
+│   └── … 3 more pages
+└── docs/v1
+    └── … 2 more pages
+https://other.com
+└── search?q=1
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := testTree(t).WriteASCII(buf, tt.options...); err != nil {
+				t.Fatalf("WriteASCII failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("tree is:\n%s\nwant:\n%s", buf, tt.want)
+			}
+		})
+	}
+}
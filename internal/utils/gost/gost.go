@@ -20,17 +20,25 @@ type StatusBar struct {
 	isTTY    bool
 	widgets  []Widget
 	disabled bool
+	out      *os.File
 }
 
 // NewStatusBar makes a new status bar with desired UI and settings
 func NewStatusBar(tickRate time.Duration, widgets ...Widget) *StatusBar {
 	return &StatusBar{
 		tickRate: tickRate,
-		isTTY:    isTTY(),
+		isTTY:    isTTY(os.Stdout),
 		widgets:  widgets,
+		out:      os.Stdout,
 	}
 }
 
+// SetOutput makes status bar write to f instead of Stdout, which is useful when Stdout is used for the program output
+func (sb *StatusBar) SetOutput(f *os.File) {
+	sb.out = f
+	sb.isTTY = isTTY(f)
+}
+
 // Run forces progress bar to tick
 func (sb *StatusBar) Run() {
 	go func() {
@@ -58,8 +66,8 @@ func (sb *StatusBar) render() []byte {
 }
 
 func (sb *StatusBar) remove() {
-	output := []byte(fmt.Sprintf("\r%s", overwriteBlank([]byte{})))
-	os.Stdout.Write(output)
+	output := []byte(fmt.Sprintf("\r%s", sb.overwriteBlank([]byte{})))
+	sb.out.Write(output)
 }
 
 func (sb *StatusBar) print() error {
@@ -67,21 +75,21 @@ func (sb *StatusBar) print() error {
 		return nil
 	}
 	output := fmt.Sprintf("\r%s", sb.render())
-	_, err := os.Stdout.Write(overwriteBlank([]byte(output)))
+	_, err := sb.out.Write(sb.overwriteBlank([]byte(output)))
 	return err
 }
 
-func isTTY() bool {
-	return terminal.IsTerminal(int(os.Stdout.Fd()))
+func isTTY(f *os.File) bool {
+	return terminal.IsTerminal(int(f.Fd()))
 }
 
-func getTerminalWidth() int {
-	w, _, _ := terminal.GetSize(int(os.Stdout.Fd()))
+func getTerminalWidth(f *os.File) int {
+	w, _, _ := terminal.GetSize(int(f.Fd()))
 	return w
 }
 
-func overwriteBlank(data []byte) []byte {
-	remainder := getTerminalWidth() - len(data)
+func (sb *StatusBar) overwriteBlank(data []byte) []byte {
+	remainder := getTerminalWidth(sb.out) - len(data)
 	if remainder <= 0 {
 		return data
 	}
@@ -93,8 +101,8 @@ func overwriteBlank(data []byte) []byte {
 // It returns the number of bytes written and an error, if any.
 // Write returns a non-nil error when n != len(b).
 func (sb *StatusBar) Write(data []byte) (int, error) {
-	output := []byte(fmt.Sprintf("\r%s\n", overwriteBlank(data)))
-	n, err := os.Stdout.Write([]byte(output))
+	output := []byte(fmt.Sprintf("\r%s\n", sb.overwriteBlank(data)))
+	n, err := sb.out.Write([]byte(output))
 	if err != nil {
 		return 0, err
 	}
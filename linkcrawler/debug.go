@@ -0,0 +1,96 @@
+package linkcrawler
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultCaptureBody is the number of bytes of response body captured for failures written into debug directory unless OptionCaptureBody says otherwise
+const DefaultCaptureBody = 64 * 1024
+
+// WriteHTTP writes the failed exchange into w in the format of .http files, so the request can be repeated with HTTP clients of IDEs.
+// The response follows the request as comments
+func (fe *FetchError) WriteHTTP(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if len(fe.RequestURLs) > 1 {
+		fmt.Fprintf(bw, "# Redirected from %s\n", strings.Join(fe.RequestURLs[:len(fe.RequestURLs)-1], " -> "))
+	}
+	lines := dumpLines(fe.RequestDump)
+	for i, line := range lines {
+		if i == 0 && len(fe.RequestURLs) > 0 {
+			// Request line of the dump holds only the path, .http files need the full URL
+			if parts := strings.SplitN(line, " ", 3); len(parts) == 3 {
+				line = strings.Join([]string{parts[0], fe.RequestURLs[len(fe.RequestURLs)-1], parts[2]}, " ")
+			}
+		}
+		fmt.Fprintln(bw, line)
+	}
+	fmt.Fprintln(bw)
+	for _, line := range dumpLines(fe.ResponseDump) {
+		fmt.Fprintf(bw, "# %s\n", line)
+	}
+	if len(fe.Body) > 0 {
+		fmt.Fprintln(bw, "#")
+		for _, line := range dumpLines(fe.Body) {
+			fmt.Fprintf(bw, "# %s\n", line)
+		}
+		if fe.BodyTruncated {
+			fmt.Fprintln(bw, "# ... (truncated)")
+		}
+	}
+	return bw.Flush()
+}
+
+// dumpLines splits the dump into lines dropping the trailing empty ones
+func dumpLines(dump []byte) []string {
+	dump = bytes.TrimRight(dump, "\r\n")
+	if len(dump) == 0 {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(string(dump), "\r\n", "\n"), "\n")
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+const maxDebugNameLength = 100
+
+// debugWriter writes failed exchanges into the directory, one .http file per failure
+type debugWriter struct {
+	dir   string
+	count int64
+}
+
+func newDebugWriter(dir string) (*debugWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &debugWriter{dir: dir}, nil
+}
+
+// write writes the exchange into the file named after the sequence number of the failure and the requested URL.
+// RequestURLs must be set, see FetchError.withURL
+func (dw *debugWriter) write(fe *FetchError) error {
+	num := atomic.AddInt64(&dw.count, 1)
+	name := fe.RequestURLs[0]
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "http://"), "https://")
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > maxDebugNameLength {
+		name = name[:maxDebugNameLength]
+	}
+	f, err := os.Create(filepath.Join(dw.dir, fmt.Sprintf("%04d-%s.http", num, name)))
+	if err != nil {
+		return err
+	}
+	if err := fe.WriteHTTP(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,152 @@
+package linkcrawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// ErrorCategory is the kind of failure of crawling the page
+type ErrorCategory string
+
+const (
+	CategoryDNS               ErrorCategory = "dns"
+	CategoryTimeout           ErrorCategory = "timeout"
+	CategoryTLS               ErrorCategory = "tls"
+	CategoryConnectionRefused ErrorCategory = "connection refused"
+	CategoryNetwork           ErrorCategory = "network"
+	CategoryRedirect          ErrorCategory = "redirect"
+	// CategoryClientError is the category of HTTP 4xx responses
+	CategoryClientError ErrorCategory = "client error"
+	// CategoryServerError is the category of HTTP 5xx responses
+	CategoryServerError ErrorCategory = "server error"
+	// CategoryParse is the category of pages and links that failed to parse
+	CategoryParse ErrorCategory = "parse"
+	CategoryOther ErrorCategory = "other"
+)
+
+// Sentinel errors matching the errors of search results of the corresponding categories with errors.Is
+var (
+	ErrDNS               = errors.New("DNS lookup failed")
+	ErrTimeout           = errors.New("request timed out")
+	ErrTLS               = errors.New("TLS handshake failed")
+	ErrConnectionRefused = errors.New("connection refused")
+	ErrNetwork           = errors.New("network error")
+	ErrTooManyRedirects  = errors.New("too many redirects")
+	ErrClientError       = errors.New("client error")
+	ErrServerError       = errors.New("server error")
+	ErrParse             = errors.New("parse error")
+)
+
+var categorySentinels = map[ErrorCategory]error{
+	CategoryDNS:               ErrDNS,
+	CategoryTimeout:           ErrTimeout,
+	CategoryTLS:               ErrTLS,
+	CategoryConnectionRefused: ErrConnectionRefused,
+	CategoryNetwork:           ErrNetwork,
+	CategoryRedirect:          ErrTooManyRedirects,
+	CategoryClientError:       ErrClientError,
+	CategoryServerError:       ErrServerError,
+	CategoryParse:             ErrParse,
+}
+
+// CrawlError is the error of search result. It wraps the original error, which is available with errors.As
+// (like *FetchError, links.LinkParseError or *net.DNSError), and matches the sentinel error of its category with errors.Is
+type CrawlError struct {
+	URL      string
+	Category ErrorCategory
+	Err      error
+}
+
+func (ce *CrawlError) Error() string {
+	return ce.Err.Error()
+}
+
+// Unwrap returns the original error
+func (ce *CrawlError) Unwrap() error {
+	return ce.Err
+}
+
+// Is reports whether target is the sentinel error of the error category
+func (ce *CrawlError) Is(target error) bool {
+	sentinel, ok := categorySentinels[ce.Category]
+	return ok && target == sentinel
+}
+
+// Is makes FetchError match ErrClientError or ErrServerError depending on the status code
+func (fe *FetchError) Is(target error) bool {
+	if fe.Code >= 500 {
+		return target == ErrServerError
+	}
+	return target == ErrClientError
+}
+
+// newCrawlError wraps the error occurred while crawling the page at addr
+func newCrawlError(addr string, err error) *CrawlError {
+	return &CrawlError{
+		URL:      addr,
+		Category: Categorize(err),
+		Err:      err,
+	}
+}
+
+// Categorize tells the category of any error occurred while fetching or parsing the page
+func Categorize(err error) ErrorCategory {
+	var crawlErr *CrawlError
+	if errors.As(err, &crawlErr) {
+		return crawlErr.Category
+	}
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		if fe.Code >= 500 {
+			return CategoryServerError
+		}
+		return CategoryClientError
+	}
+	var parseErr links.LinkParseError
+	if errors.As(err, &parseErr) {
+		return CategoryParse
+	}
+	if errors.Is(err, ErrTooManyRedirects) {
+		return CategoryRedirect
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return CategoryDNS
+	}
+	if isTLSError(err) {
+		return CategoryTLS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return CategoryConnectionRefused
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return CategoryTimeout
+	}
+	var urlErr *url.Error
+	var opErr *net.OpError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) {
+		return CategoryNetwork
+	}
+	return CategoryOther
+}
+
+func isTLSError(err error) bool {
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &recordErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return true
+	}
+	// Handshake failures reported by the server are not typed
+	return strings.Contains(err.Error(), "tls: ")
+}
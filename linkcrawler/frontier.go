@@ -0,0 +1,279 @@
+package linkcrawler
+
+import (
+	"container/heap"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// task is a page waiting in the frontier to be visited
+type task struct {
+	url      url.URL
+	hops     int
+	source   Source
+	referrer string
+}
+
+// frontierItem is the task in the priority queue of frontier
+type frontierItem struct {
+	task  task
+	score float64
+	// seq is the number of the task in the order of pushing, it keeps the pages with equal scores in that order
+	seq uint64
+	// index is the position of the item in the queue
+	index int
+}
+
+// taskQueue implements heap.Interface, the item with the highest score is on top
+type taskQueue []*frontierItem
+
+func (q taskQueue) Len() int { return len(q) }
+
+func (q taskQueue) Less(i, j int) bool {
+	if q[i].score != q[j].score {
+		return q[i].score > q[j].score
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q taskQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *taskQueue) Push(x interface{}) {
+	item := x.(*frontierItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *taskQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	// The taken item is cleared so the queue doesn't keep it in the underlying array
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
+
+// frontier is the priority queue of pages to visit shared by the workers, the pages are taken in the order of their scores.
+// Besides the queued tasks it counts the ones being visited, since they may add new tasks, so the crawl is only complete when both are gone.
+// The page budget is spent as the tasks are taken, so the queued pages compete on their scores till the budget is over
+type frontier struct {
+	mut   sync.Mutex
+	cond  *sync.Cond
+	score ScoreFunc
+	queue taskQueue
+	// queued holds the items of the queue by URL, so they can be rated again when new links to them are found
+	queued map[string]*frontierItem
+	// inbound is the number of links found to each URL
+	inbound map[string]int
+	seq     uint64
+	active  int
+	closed  bool
+	// budget is the number of tasks to hand out, 0 means no limit
+	budget uint
+	taken  uint
+}
+
+func newFrontier(score ScoreFunc, budget uint) *frontier {
+	f := &frontier{
+		score:   score,
+		budget:  budget,
+		queued:  make(map[string]*frontierItem),
+		inbound: make(map[string]int),
+	}
+	f.cond = sync.NewCond(&f.mut)
+	return f
+}
+
+// rate returns the score of the task
+func (f *frontier) rate(t task) float64 {
+	return f.score(Candidate{
+		URL:      t.url,
+		Hops:     t.hops,
+		Source:   t.source,
+		Referrer: t.referrer,
+		Inbound:  f.inbound[t.url.String()],
+	})
+}
+
+// push adds the tasks to the queue. Tasks pushed after the frontier is closed are dropped
+func (f *frontier) push(tasks ...task) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	for _, t := range tasks {
+		f.enqueue(t)
+	}
+	f.cond.Broadcast()
+}
+
+// pushLinks counts the links found on a page and adds the pages they lead to into the queue if admit accepts them,
+// rating the queued ones again. All links of the page are added at once, so none of them is taken before the better rated ones are queued
+func (f *frontier) pushLinks(tasks []task, admit func(task) bool) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	for _, t := range tasks {
+		key := t.url.String()
+		f.inbound[key]++
+		if item, ok := f.queued[key]; ok {
+			item.score = f.rate(item.task)
+			heap.Fix(&f.queue, item.index)
+		} else if admit(t) {
+			f.enqueue(t)
+		}
+	}
+	f.cond.Broadcast()
+}
+
+// enqueue adds the task to the queue, the caller must hold the lock
+func (f *frontier) enqueue(t task) {
+	if f.closed {
+		return
+	}
+	item := &frontierItem{task: t, score: f.rate(t), seq: f.seq}
+	f.seq++
+	heap.Push(&f.queue, item)
+	f.queued[t.url.String()] = item
+}
+
+// next waits for a task and takes the one with the highest score from the queue. The caller must call done once the task is visited.
+// ok is false when the crawl is complete or the frontier is closed, the worker should exit then
+func (f *frontier) next() (t task, ok bool) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	for len(f.queue) == 0 && !f.closed {
+		if f.active == 0 {
+			// Nothing is queued and nobody can queue anything anymore
+			f.closed = true
+			f.cond.Broadcast()
+			break
+		}
+		f.cond.Wait()
+	}
+	if !f.closed && f.budget > 0 && f.taken >= f.budget {
+		// The rest of the queued pages are dropped, the pages being visited may still add more but they are dropped as well
+		f.closed = true
+		f.queue = nil
+		f.queued = make(map[string]*frontierItem)
+		f.cond.Broadcast()
+	}
+	if f.closed {
+		return task{}, false
+	}
+	t = heap.Pop(&f.queue).(*frontierItem).task
+	delete(f.queued, t.url.String())
+	f.active++
+	f.taken++
+	return t, true
+}
+
+// done marks the task taken with next as visited
+func (f *frontier) done() {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.active--
+	if f.active == 0 && len(f.queue) == 0 {
+		// Wake up the idle workers so they see the crawl is complete
+		f.cond.Broadcast()
+	}
+}
+
+// close drops the queued tasks and makes workers exit as soon as they are done with their current tasks
+func (f *frontier) close() {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.closed = true
+	f.queue = nil
+	f.queued = make(map[string]*frontierItem)
+	f.cond.Broadcast()
+}
+
+// crawlLevels visits the website level by level starting from the given page, see OptionDeterministic.
+// It closes the output channel when the crawl is complete or cancelled
+func (crawler *linkCrawler) crawlLevels(start task, seeds []task, workers uint) {
+	defer crawler.finish()
+	level := []task{start}
+	visited := uint(0)
+	for len(level) > 0 {
+		visited += uint(len(level))
+		sort.SliceStable(level, func(i, j int) bool {
+			return level[i].url.String() < level[j].url.String()
+		})
+		results := make([][]SearchResult, len(level))
+		found := make([][]task, len(level))
+		indexes := make(chan int)
+		wg := &sync.WaitGroup{}
+		for i := uint(0); i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					results[i], found[i] = crawler.visit(level[i])
+				}
+			}()
+		}
+	feed:
+		for i := range level {
+			select {
+			case indexes <- i:
+			case <-crawler.ctx.Done():
+				break feed
+			}
+		}
+		close(indexes)
+		wg.Wait()
+
+		// The pages of the next level are taken in the order of their scores, so the page cap leaves out the least rated ones
+		candidates := make([]task, 0)
+		inbound := make(map[string]int)
+		for i := range level {
+			if !crawler.sendAll(results[i]) {
+				return
+			}
+			for _, t := range found[i] {
+				key := t.url.String()
+				if inbound[key] == 0 {
+					candidates = append(candidates, t)
+				}
+				inbound[key]++
+			}
+		}
+		if seeds != nil {
+			candidates = append(seeds, candidates...)
+			seeds = nil
+		}
+		items := make([]frontierItem, len(candidates))
+		for i, t := range candidates {
+			items[i] = frontierItem{task: t, seq: uint64(i), score: crawler.score(Candidate{
+				URL:      t.url,
+				Hops:     t.hops,
+				Source:   t.source,
+				Referrer: t.referrer,
+				Inbound:  inbound[t.url.String()],
+			})}
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].score != items[j].score {
+				return items[i].score > items[j].score
+			}
+			ui, uj := items[i].task.url.String(), items[j].task.url.String()
+			if ui != uj {
+				return ui < uj
+			}
+			return items[i].seq < items[j].seq
+		})
+		next := make([]task, 0)
+		for _, item := range items {
+			if crawler.maxPages > 0 && visited+uint(len(next)) >= crawler.maxPages {
+				break
+			}
+			if crawler.admit(item.task) {
+				next = append(next, item.task)
+			}
+		}
+		level = next
+	}
+}
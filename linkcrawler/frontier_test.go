@@ -0,0 +1,158 @@
+package linkcrawler
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func testTask(t *testing.T, addr string, hops int) task {
+	t.Helper()
+	u, err := url.Parse(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task{url: *u, hops: hops, source: SourceLink}
+}
+
+// takeAll takes the tasks from the frontier till it's empty, marking each one done, and returns their URLs
+func takeAll(f *frontier) []string {
+	taken := make([]string, 0)
+	for {
+		t, ok := f.next()
+		if !ok {
+			return taken
+		}
+		taken = append(taken, t.url.String())
+		f.done()
+	}
+}
+
+func TestFrontierOrder(t *testing.T) {
+	f := newFrontier(NewScoreFunc(ScoreWeights{
+		Depth:    -10,
+		Patterns: map[string]float64{"/docs/": 100},
+	}), 0)
+	f.push(
+		testTask(t, "https://example.com/a", 1),
+		testTask(t, "https://example.com/b/c", 2),
+		testTask(t, "https://example.com/b", 1),
+		testTask(t, "https://example.com/docs/d", 3),
+	)
+	want := []string{"https://example.com/docs/d", "https://example.com/a", "https://example.com/b", "https://example.com/b/c"}
+	if got := takeAll(f); !equalStrings(got, want) {
+		t.Errorf("tasks are taken in order %v, want %v", got, want)
+	}
+}
+
+func TestFrontierPushLinks(t *testing.T) {
+	f := newFrontier(NewScoreFunc(ScoreWeights{Inbound: 1}), 0)
+	admitted := make(map[string]bool)
+	admit := func(t task) bool {
+		key := t.url.String()
+		if admitted[key] {
+			return false
+		}
+		admitted[key] = true
+		return true
+	}
+	a, b := testTask(t, "https://example.com/a", 1), testTask(t, "https://example.com/b", 1)
+	f.pushLinks([]task{a, b}, admit)
+	// The second link to the queued page moves it forward
+	f.pushLinks([]task{b}, admit)
+
+	want := []string{"https://example.com/b", "https://example.com/a"}
+	if got := takeAll(f); !equalStrings(got, want) {
+		t.Errorf("tasks are taken in order %v, want %v", got, want)
+	}
+	// The visited page is not queued again
+	f = newFrontier(NewScoreFunc(ScoreWeights{}), 0)
+	f.pushLinks([]task{a}, admit)
+	if got := takeAll(f); len(got) != 0 {
+		t.Errorf("tasks %v are taken, want none", got)
+	}
+}
+
+func TestFrontierBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget uint
+		want   int
+	}{
+		{"no limit", 0, 3},
+		{"below the number of tasks", 2, 2},
+		{"above the number of tasks", 5, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFrontier(NewScoreFunc(DefaultScoreWeights), tt.budget)
+			f.push(
+				testTask(t, "https://example.com/a", 1),
+				testTask(t, "https://example.com/b", 1),
+				testTask(t, "https://example.com/c", 1),
+			)
+			if got := takeAll(f); len(got) != tt.want {
+				t.Errorf("%d tasks are taken, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+// nextAsync takes the task in the background, the result is sent once next returns
+func nextAsync(f *frontier) <-chan bool {
+	result := make(chan bool, 1)
+	go func() {
+		_, ok := f.next()
+		result <- ok
+	}()
+	return result
+}
+
+func TestFrontierComplete(t *testing.T) {
+	f := newFrontier(NewScoreFunc(DefaultScoreWeights), 0)
+	f.push(testTask(t, "https://example.com/", 0))
+	if _, ok := f.next(); !ok {
+		t.Fatal("the queued task is not taken")
+	}
+
+	// The idle worker waits while the task being visited may add new ones
+	waiting := nextAsync(f)
+	select {
+	case <-waiting:
+		t.Fatal("next returned while the task is being visited")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	f.done()
+	select {
+	case ok := <-waiting:
+		if ok {
+			t.Error("next returned a task after the crawl is complete")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("next is still waiting after the crawl is complete")
+	}
+}
+
+func TestFrontierClose(t *testing.T) {
+	f := newFrontier(NewScoreFunc(DefaultScoreWeights), 0)
+	f.push(testTask(t, "https://example.com/", 0))
+	f.next()
+
+	waiting := nextAsync(f)
+	f.close()
+	select {
+	case ok := <-waiting:
+		if ok {
+			t.Error("next returned a task after the frontier is closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("next is still waiting after the frontier is closed")
+	}
+
+	f.push(testTask(t, "https://example.com/a", 1))
+	f.done()
+	if got := takeAll(f); len(got) != 0 {
+		t.Errorf("tasks %v pushed after closing are taken", got)
+	}
+}
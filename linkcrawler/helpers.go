@@ -1,6 +1,7 @@
 package linkcrawler
 
 import (
+	"io"
 	"net/url"
 	"strings"
 	"sync"
@@ -66,3 +67,15 @@ func (h *history) Entries() []string {
 	}
 	return entries
 }
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,129 @@
+package linkcrawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Request is the page about to be fetched, it's passed to OnRequest hooks
+type Request struct {
+	URL      string
+	Hops     int
+	Source   Source
+	Referrer string
+	// Header holds the headers to add to the request, hooks may change it. Fetcher gets them with RequestHeader
+	Header http.Header
+}
+
+// Discovery is the page found on the crawled page, it's passed to OnLinkDiscovered hooks
+type Discovery struct {
+	// URL is the address of the found page resolved against the crawled page, hooks may change it to follow another link instead
+	URL  url.URL
+	Hops int
+	// Source tells if the page is found by the link or as language alternate
+	Source Source
+	// Referrer is the URL of the crawled page
+	Referrer string
+}
+
+// RequestHook is called before the page is fetched. The headers it sets reach a custom Fetcher only through RequestHeader(ctx)
+type RequestHook func(ctx context.Context, req *Request)
+
+// ResponseHook is called when the page is fetched, before it's parsed. body is the whole body of the response, which the hook must not change.
+// err is the error of fetching the page: for 4xx and 5xx responses it's *FetchError and res is rebuilt from it with the captured part of the body,
+// if no response arrived res is nil, and if reading the body failed body is the part read
+type ResponseHook func(ctx context.Context, res *http.Response, body []byte, err error)
+
+// LinkHook is called for every page found on the crawled page before it's checked against the search options.
+// It returns false to skip the page
+type LinkHook func(ctx context.Context, d *Discovery) bool
+
+// ResultHook is called before the search result is sent to the output, it may change the result
+type ResultHook func(ctx context.Context, res *SearchResult)
+
+// FinishHook is called once the crawl is over, right before the output channel is closed.
+// err is the error of the crawl context if the crawl was cancelled, and nil if it's complete
+type FinishHook func(ctx context.Context, err error)
+
+// Hooks are the callbacks crawler runs during the crawl. Hooks of each kind are run one after another in the order they were added.
+// For every page, OnRequest hooks are run first, then OnResponse hooks once the page is fetched or fails, then OnLinkDiscovered hooks for the pages it links to
+// in the order of the links, and then OnResult hooks for its search results. OnFinish hooks are run when all pages are done.
+// Pages are crawled in parallel, so the hooks of different pages run concurrently and must be safe for that.
+// All hooks get the context of the crawl
+type Hooks struct {
+	OnRequest        []RequestHook
+	OnResponse       []ResponseHook
+	OnLinkDiscovered []LinkHook
+	OnResult         []ResultHook
+	OnFinish         []FinishHook
+}
+
+type contextKey int
+
+// requestHeaderKey is the context key of the headers set by OnRequest hooks
+const requestHeaderKey contextKey = 0
+
+// RequestHeader returns the headers that OnRequest hooks set for the request made with ctx, or nil if there are none.
+// Fetcher implementations should add them to their requests
+func RequestHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderKey).(http.Header)
+	return header
+}
+
+// request runs OnRequest hooks and returns the context to fetch the page with
+func (h *Hooks) request(ctx context.Context, t task) context.Context {
+	if len(h.OnRequest) == 0 {
+		return ctx
+	}
+	req := &Request{
+		URL:      t.url.String(),
+		Hops:     t.hops,
+		Source:   t.source,
+		Referrer: t.referrer,
+		Header:   make(http.Header),
+	}
+	for _, hook := range h.OnRequest {
+		hook(ctx, req)
+	}
+	if len(req.Header) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestHeaderKey, req.Header)
+}
+
+// response runs OnResponse hooks
+func (h *Hooks) response(ctx context.Context, res *http.Response, body []byte, err error) {
+	for _, hook := range h.OnResponse {
+		hook(ctx, res, body, err)
+	}
+}
+
+// linkDiscovered runs OnLinkDiscovered hooks and returns the page to follow, ok is false if some hook skipped it
+func (h *Hooks) linkDiscovered(ctx context.Context, t task) (next task, ok bool) {
+	if len(h.OnLinkDiscovered) == 0 {
+		return t, true
+	}
+	d := &Discovery{URL: t.url, Hops: t.hops, Source: t.source, Referrer: t.referrer}
+	for _, hook := range h.OnLinkDiscovered {
+		if !hook(ctx, d) {
+			return t, false
+		}
+	}
+	t.url = d.URL
+	return t, true
+}
+
+// result runs OnResult hooks
+func (h *Hooks) result(ctx context.Context, res *SearchResult) {
+	for _, hook := range h.OnResult {
+		hook(ctx, res)
+	}
+}
+
+// finish runs OnFinish hooks
+func (h *Hooks) finish(ctx context.Context) {
+	for _, hook := range h.OnFinish {
+		hook(ctx, ctx.Err())
+	}
+}
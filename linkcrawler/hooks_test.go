@@ -0,0 +1,130 @@
+package linkcrawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	site := newTestSite(t, map[string][]string{
+		"/":    {"/old", "/skip", "/missing"},
+		"/new": {},
+		"/old": {},
+	})
+
+	var mut sync.Mutex
+	responses := make(map[string]int)
+	responseErrors := make(map[string]error)
+	finished := 0
+	results, err := Crawl(context.Background(), site.URL+"/",
+		OptionOnRequest(func(ctx context.Context, req *Request) {
+			req.Header.Set("X-Hops", strings.Repeat("+", req.Hops))
+		}),
+		OptionOnResponse(func(ctx context.Context, res *http.Response, body []byte, err error) {
+			mut.Lock()
+			defer mut.Unlock()
+			path := res.Request.URL.Path
+			responses[path] = res.StatusCode
+			responseErrors[path] = err
+		}),
+		OptionOnLinkDiscovered(func(ctx context.Context, d *Discovery) bool {
+			if d.URL.Path == "/old" {
+				d.URL.Path = "/new"
+			}
+			return d.URL.Path != "/skip"
+		}),
+		OptionOnResult(func(ctx context.Context, res *SearchResult) {
+			res.Meta.Title = "changed"
+		}),
+		OptionOnFinish(func(ctx context.Context, err error) {
+			if err != nil {
+				t.Errorf("OnFinish got %v for the complete crawl", err)
+			}
+			finished++
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	byPath := make(map[string]SearchResult)
+	for res := range results {
+		byPath[pathOf(t, res.Addr)] = res
+	}
+
+	if finished != 1 {
+		t.Errorf("OnFinish is called %d times", finished)
+	}
+	if len(byPath) != 3 {
+		t.Errorf("crawled %d pages, want 3", len(byPath))
+	}
+	for path, res := range byPath {
+		if res.Meta.Title != "changed" {
+			t.Errorf("%s result is not changed by OnResult", path)
+		}
+	}
+
+	// OnRequest
+	site.mut.Lock()
+	if hops := site.headers["/new"].Get("X-Hops"); hops != "+" {
+		t.Errorf("/new is requested with header %q", hops)
+	}
+	site.mut.Unlock()
+
+	// OnResponse runs for failed responses as well
+	if responses["/"] != http.StatusOK || responseErrors["/"] != nil {
+		t.Errorf("start page response is %d, %v", responses["/"], responseErrors["/"])
+	}
+	var fe *FetchError
+	if responses["/missing"] != http.StatusNotFound || !errors.As(responseErrors["/missing"], &fe) {
+		t.Errorf("missing page response is %d, %v", responses["/missing"], responseErrors["/missing"])
+	}
+
+	// OnLinkDiscovered
+	for _, path := range site.requests() {
+		if path == "/old" || path == "/skip" {
+			t.Errorf("%s is requested", path)
+		}
+	}
+	links := byPath["/"].Links
+	if len(links) != 3 {
+		t.Fatalf("start page has %d links, want 3", len(links))
+	}
+	if links[0].URL.Path != "/new" {
+		t.Errorf("the rewritten link is kept as %s", links[0].URL.String())
+	}
+	if links[1].URL.Path != "/skip" {
+		t.Errorf("the skipped link is kept as %s", links[1].URL.String())
+	}
+}
+
+func TestRequestHeader(t *testing.T) {
+	site := newTestSite(t, map[string][]string{"/": {}})
+	var got http.Header
+	fetcher := FetcherFunc(func(ctx context.Context, addr string) (*http.Response, error) {
+		got = RequestHeader(ctx)
+		return NewHTTPFetcher(0).Fetch(ctx, addr)
+	})
+	results, err := Crawl(context.Background(), site.URL+"/",
+		OptionFetcher(fetcher),
+		OptionOnRequest(func(ctx context.Context, req *Request) {
+			req.Header.Set("Authorization", "Bearer token")
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	for range results {
+	}
+	if got.Get("Authorization") != "Bearer token" {
+		t.Errorf("custom fetcher got headers %v", got)
+	}
+	site.mut.Lock()
+	defer site.mut.Unlock()
+	if auth := site.headers["/"].Get("Authorization"); auth != "Bearer token" {
+		t.Errorf("page is requested with Authorization %q", auth)
+	}
+}
@@ -0,0 +1,964 @@
+// Package linkcrawler crawls websites by following the links between their pages.
+// Crawl starts the crawl and sends a SearchResult for every page found, functional options (Option*) configure what is crawled and collected
+package linkcrawler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TofuOverdose/WebMapMaker/links"
+)
+
+// SearchConfig specifies link acceptance critereas for crawler
+type SearchConfig struct {
+	IncludeSubdomains     bool
+	IgnoreTopLevelDomain  bool
+	IncludeLinksWithQuery bool
+	ExcludedPaths         []string
+}
+
+// FetchError carries data about HTTP response with 4xx or 5xx status codes.
+// Fetcher implementations must set Code and Status, the rest of fields are optional
+type FetchError struct {
+	Code   int
+	Status string
+	// RequestURLs are the URLs requested from the first one to the one the response came from, following the redirects.
+	// The crawler takes the requested URL if they are not set
+	RequestURLs []string
+	// RequestDump and ResponseDump are the request and the response without bodies as sent over the wire
+	RequestDump  []byte
+	ResponseDump []byte
+	// Header is the header of the response
+	Header http.Header
+	// Body is the beginning of the response body, it's only captured with OptionCaptureBody
+	Body []byte
+	// BodyTruncated tells that the response body is longer than the captured part
+	BodyTruncated bool
+}
+
+func (fe *FetchError) Error() string {
+	if len(fe.RequestURLs) == 0 {
+		return fmt.Sprintf("Fetch error: %s", fe.Status)
+	}
+	firstReq := fe.RequestURLs[0]
+	lastReq := fe.RequestURLs[len(fe.RequestURLs)-1]
+	if firstReq != lastReq {
+		return fmt.Sprintf("Fetch error from %s (original request for %s): %s", lastReq, firstReq, fe.Status)
+	}
+	return fmt.Sprintf("Fetch error from %s: %s", lastReq, fe.Status)
+}
+
+// withURL returns the copy of the error with RequestURLs set to addr, for the errors the fetcher returned without them.
+// The error is copied rather than changed, since the fetcher may return the same one for many pages
+func (fe *FetchError) withURL(addr string) *FetchError {
+	filled := *fe
+	filled.RequestURLs = []string{addr}
+	return &filled
+}
+
+// response rebuilds the failed response for OnResponse hooks, its body is the captured part of the original one.
+// RequestURLs must be set, see withURL
+func (fe *FetchError) response() *http.Response {
+	res := &http.Response{
+		Status:        fe.Status,
+		StatusCode:    fe.Code,
+		Header:        fe.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(fe.Body)),
+		ContentLength: -1,
+	}
+	if u, err := url.Parse(fe.RequestURLs[len(fe.RequestURLs)-1]); err == nil {
+		res.Request = &http.Request{Method: http.MethodGet, URL: u}
+	}
+	return res
+}
+
+// Fetcher loads pages for the crawler, it might be an adapter for headless browser to fetch SPAs, or a client with custom headers or authentication.
+// Fetch returns the response with the requested page, the crawler closes its body. The request should be aborted when ctx is cancelled.
+// The response should have Request set to the request the page was finally loaded with, so relative links of redirected pages are resolved right.
+// Responses with 4xx and 5xx status codes should be returned as *FetchError for the crawler to report them, see FetchError for the fields to set.
+// The headers set by OnRequest hooks only reach the fetcher through ctx, Fetch should add RequestHeader(ctx) to its requests
+type Fetcher interface {
+	Fetch(ctx context.Context, addr string) (*http.Response, error)
+}
+
+// FetcherFunc is an adapter to use ordinary functions as Fetcher
+type FetcherFunc func(ctx context.Context, addr string) (*http.Response, error)
+
+// Fetch calls f(ctx, addr)
+func (f FetcherFunc) Fetch(ctx context.Context, addr string) (*http.Response, error) {
+	return f(ctx, addr)
+}
+
+// filterFunc decides whether or not the received url should be passed based on certain criterias
+type filterFunc func(url.URL) bool
+
+const defaultMaxRedirects = 10
+
+// NewHTTPFetcher makes Fetcher that uses http package from standard library for fetching static pages. It's used by crawler unless OptionFetcher is given.
+// Up to captureBody bytes of response body are kept in FetchError, 0 turns it off
+func NewHTTPFetcher(captureBody int64) Fetcher {
+	return FetcherFunc(func(ctx context.Context, addr string) (*http.Response, error) {
+		return fetchHTTP(ctx, addr, captureBody)
+	})
+}
+
+func fetchHTTP(ctx context.Context, addr string, captureBody int64) (*http.Response, error) {
+	reCount := 0
+	urls := []string{addr}
+	client := http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			reCount++
+			if reCount == defaultMaxRedirects {
+				return fmt.Errorf("HTTP client exceeded maximum of %d redirects (initial request for %s): %w", defaultMaxRedirects, addr, ErrTooManyRedirects)
+			}
+			urls = append(urls, req.URL.String())
+			return nil
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range RequestHeader(ctx) {
+		req.Header[name] = values
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 400 {
+		reqDump, _ := httputil.DumpRequestOut(res.Request, false)
+		resDump, _ := httputil.DumpResponse(res, false)
+		fe := &FetchError{
+			Code:         res.StatusCode,
+			Status:       res.Status,
+			RequestURLs:  urls,
+			RequestDump:  reqDump,
+			ResponseDump: resDump,
+			Header:       res.Header,
+		}
+		if captureBody > 0 {
+			// One more byte is read to tell if the body is truncated
+			body, _ := ioutil.ReadAll(io.LimitReader(res.Body, captureBody+1))
+			if int64(len(body)) > captureBody {
+				body = body[:captureBody]
+				fe.BodyTruncated = true
+			}
+			fe.Body = body
+		}
+		res.Body.Close()
+		return nil, fe
+	}
+
+	return res, nil
+}
+
+// linkCrawler is the main 'context' of operations
+type linkCrawler struct {
+	// links hrefs need to be compared with the initial url
+	initURL *url.URL
+	// fetcher encapsulates data fetching and is configurable with OptionFetcher
+	fetcher Fetcher
+	// I thought it's also pretty convinient to keep filtering strategy separate
+	filterFunc filterFunc
+	// scopeFunc decides if the resources referenced by pages (images, etc.) belong to the crawled website
+	scopeFunc filterFunc
+	// collectImages turns on gathering of images found on pages
+	collectImages bool
+	// collectVideos turns on gathering of videos embedded into pages
+	collectVideos bool
+	// collectAlternates turns on gathering of language alternates of pages
+	collectAlternates bool
+	// collectAssets turns on gathering of resources pages depend on
+	collectAssets bool
+	// collectAnchors turns on gathering of element ids of pages and following links with fragments
+	collectAnchors bool
+	// headers are the names of response headers kept in search results
+	headers []string
+	// debug writes failed exchanges into debug directory, it's nil unless OptionDebugDir is given
+	debug *debugWriter
+	// history is a hash map holding all previously visited urls to prevent going through it again. See ./helpers.go
+	history *history
+	// maxDepth is the maximum number of hops from the start page to the visited pages, it's negative if there is no limit
+	maxDepth int
+	// maxPages is the maximum number of pages to visit, 0 means no limit
+	maxPages uint
+	// score rates the pages waiting to be visited, see ./score.go
+	score ScoreFunc
+	// frontier is the queue of pages to visit, workers take pages from it and add the newly found ones. See ./frontier.go
+	frontier *frontier
+	// outChan receives the search results
+	outChan chan SearchResult
+	// hooks are the callbacks run during the crawl, see ./hooks.go
+	hooks Hooks
+	// ctx is the context of the crawl, cancelling it aborts the requests in progress and stops the workers
+	ctx context.Context
+}
+
+// makeFilterFunc is a default factory for filterFunc for linkCrawler
+func makeFilterFunc(config SearchConfig, initURL url.URL) filterFunc {
+	initHostname := initURL.Host
+	initAddr := initURL.String()
+	return func(u url.URL) bool {
+		addr := u.String()
+		// Skip crap like this
+		if addr == "" || addr[0] == '.' {
+			return false
+		}
+
+		// Skip anchor links
+		if u.Host == "" && u.EscapedPath() == "" && u.Fragment != "" {
+			return false
+		}
+
+		if u.Host == "" {
+			newurl := initURL.ResolveReference(&u)
+			u = *newurl
+			addr = u.String()
+		}
+
+		//TODO make this configurable
+		if !strings.HasPrefix(addr, initAddr) {
+			return false
+		}
+
+		// By default, URLs with query and anchor will be ignored
+		// Not sure this is a right decision but at the moment I figured that it's certainly wrong to modify the URL assuming there should be path without query.
+		// If this assumption is true, such URL will probably be linked from some other place and eventually will be found some time later anyway.
+		if !config.IncludeLinksWithQuery && !isCleanURL(u) {
+			return false
+		}
+
+		// Check if path is excluded
+		for _, p := range config.ExcludedPaths {
+			if strings.Contains(addr, p) {
+				return false
+			}
+		}
+
+		return matchHost(config, initHostname, u.Host)
+	}
+}
+
+// matchHost checks if the hostname belongs to the crawled website according to config
+func matchHost(config SearchConfig, initHostname, hn string) bool {
+	if config.IgnoreTopLevelDomain {
+		hn = trimTopLevelDomain(hn)
+		initHostname = trimTopLevelDomain(initHostname)
+	}
+
+	if config.IncludeSubdomains && !isSubdomain(initHostname, hn) {
+		return false
+	}
+
+	return hn == initHostname
+}
+
+// makeScopeFunc is a factory for filterFunc accepting absolute URLs of resources hosted on the crawled website
+func makeScopeFunc(config SearchConfig, initURL url.URL) filterFunc {
+	return func(u url.URL) bool {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return false
+		}
+		return matchHost(config, initURL.Host, u.Host)
+	}
+}
+
+// Source tells how the crawler came to the page
+type Source string
+
+const (
+	// SourceStart is the source of the page crawling was started from
+	SourceStart Source = "start"
+	// SourceLink means that the page is linked from another page with <a href="...">
+	SourceLink Source = "link"
+	// SourceAlternate means that the page is the language alternate of another page
+	SourceAlternate Source = "alternate"
+	// SourceSitemap means that the page is listed in the sitemap given with OptionSitemapSeeds
+	SourceSitemap Source = "sitemap"
+)
+
+// DefaultResponseHeaders are the names of response headers kept in SearchResult.Headers unless OptionResponseHeaders is given
+var DefaultResponseHeaders = []string{"Last-Modified", "ETag", "Cache-Control", "Expires", "Content-Language", "X-Robots-Tag"}
+
+// SearchResult contains data about the newly found link
+type SearchResult struct {
+	Addr string
+	Hops int
+	// Error is *CrawlError if the page failed to load or parse
+	Error error
+	// Category is the kind of the error, it's empty if there is no error
+	Category ErrorCategory
+	// DebugError is set if the failed exchange could not be written into debug directory (see OptionDebugDir), the crawl goes on anyway
+	DebugError error
+	// Status is the HTTP status code of the response, it's 0 if the request failed without response
+	Status int
+	// Source tells how the page was discovered
+	Source Source
+	// Referrer is the URL of the page the crawler found the link on, it's empty for the start page
+	Referrer string
+	// FinalURL is the URL the page was loaded from after following the redirects
+	FinalURL    string
+	ContentType string
+	// ContentLength is the size of the page in bytes, it's -1 if the page was not loaded
+	ContentLength int64
+	// Headers are the response headers of interest (see OptionResponseHeaders)
+	Headers http.Header
+	// TTFB (time to first byte) is the time from sending the request till receiving the response headers
+	TTFB time.Duration
+	// DownloadTime is the time from sending the request till the whole page is received
+	DownloadTime time.Duration
+	// Meta is the metadata of the page
+	Meta links.Meta
+	// Images found on the page and hosted on the crawled website. Only collected with OptionCollectImages
+	Images []links.Image
+	// Videos embedded into the page. Only collected with OptionCollectVideos
+	Videos []links.Video
+	// Alternates are the versions of the page in other languages. Only collected with OptionCollectAlternates
+	Alternates []links.Alternate
+	// Links are all hyperlinks of the page with URLs resolved against the page URL, including the ones crawler doesn't follow
+	Links []links.Link
+	// OutboundLinks are the links of the page leading off the crawled website. Crawler never follows them
+	OutboundLinks []links.Link
+	// Assets are the images, scripts, stylesheets, fonts and media the page depends on. Only collected with OptionCollectAssets
+	Assets []links.Asset
+	// Anchors are the ids of elements of the page and names of its <a> elements, which links point to with fragments.
+	// Only collected with OptionCollectAnchors
+	Anchors []string
+}
+
+// setError sets the error of the result along with its category
+func (res *SearchResult) setError(err *CrawlError) {
+	res.Error = err
+	res.Category = err.Category
+}
+
+// work takes pages from the frontier and visits them till the crawl is complete or cancelled
+func (crawler *linkCrawler) work() {
+	for {
+		t, ok := crawler.frontier.next()
+		if !ok {
+			return
+		}
+		results, found := crawler.visit(t)
+		crawler.sendAll(results)
+		crawler.frontier.pushLinks(found, crawler.admit)
+		crawler.frontier.done()
+	}
+}
+
+// admit decides if the found page is to be queued for visiting. It's only true once for each URL, and false for the pages beyond the depth limit.
+// The page cap is not checked here, it's spent when the pages are taken from the queue
+func (crawler *linkCrawler) admit(t task) bool {
+	if crawler.maxDepth >= 0 && t.hops > crawler.maxDepth {
+		return false
+	}
+	return crawler.history.TryAdd(t.url.String())
+}
+
+// send passes the result to the output. It returns false if the crawl is cancelled and nobody reads the results anymore
+func (crawler *linkCrawler) send(res SearchResult) bool {
+	// The results of the pages aborted by cancellation are dropped, select alone might still pick the send
+	if crawler.ctx.Err() != nil {
+		return false
+	}
+	crawler.hooks.result(crawler.ctx, &res)
+	select {
+	case crawler.outChan <- res:
+		return true
+	case <-crawler.ctx.Done():
+		return false
+	}
+}
+
+// sendAll passes the results to the output in order. It returns false if the crawl is cancelled
+func (crawler *linkCrawler) sendAll(results []SearchResult) bool {
+	for _, res := range results {
+		if !crawler.send(res) {
+			return false
+		}
+	}
+	return true
+}
+
+// finish runs OnFinish hooks and closes the output
+func (crawler *linkCrawler) finish() {
+	crawler.hooks.finish(crawler.ctx)
+	close(crawler.outChan)
+}
+
+// follow adds the page found on the crawled page to found if it passes the search rules
+func (crawler *linkCrawler) follow(found []task, t task) []task {
+	if crawler.filterFunc(t.url) {
+		return append(found, t)
+	}
+	return found
+}
+
+// visit fetches the page and returns the search results for it along with the pages it leads to, in the order they appear on the page.
+// The found pages are only checked with OnLinkDiscovered hooks and filterFunc, it's up to the caller to admit them
+func (crawler *linkCrawler) visit(t task) ([]SearchResult, []task) {
+	url, hopsCount, source, referrer := t.url, t.hops, t.source, t.referrer
+	address := url.String()
+	res := SearchResult{
+		Addr:          address,
+		Hops:          hopsCount,
+		Source:        source,
+		Referrer:      referrer,
+		FinalURL:      address,
+		ContentLength: -1,
+	}
+	fetchCtx := crawler.hooks.request(crawler.ctx, t)
+	start := time.Now()
+	response, err := crawler.fetcher.Fetch(fetchCtx, address)
+	res.TTFB = time.Since(start)
+	if err != nil {
+		var fe *FetchError
+		if errors.As(err, &fe) {
+			if len(fe.RequestURLs) == 0 {
+				fe = fe.withURL(address)
+				err = fe
+			}
+			res.Status = fe.Code
+			res.FinalURL = fe.RequestURLs[len(fe.RequestURLs)-1]
+			res.ContentType = fe.Header.Get("Content-Type")
+			res.Headers = crawler.pickHeaders(fe.Header)
+			if crawler.debug != nil {
+				res.DebugError = crawler.debug.write(fe)
+			}
+			if len(crawler.hooks.OnResponse) > 0 {
+				crawler.hooks.response(crawler.ctx, fe.response(), fe.Body, err)
+			}
+		} else {
+			crawler.hooks.response(crawler.ctx, nil, nil, err)
+		}
+		res.setError(newCrawlError(address, err))
+		return []SearchResult{res}, nil
+	}
+	defer response.Body.Close()
+	res.Status = response.StatusCode
+	res.ContentType = response.Header.Get("Content-Type")
+	res.Headers = crawler.pickHeaders(response.Header)
+	if response.Request != nil {
+		// Relative links of redirected pages are resolved against the URL they were loaded from
+		url = *response.Request.URL
+		res.FinalURL = url.String()
+	}
+	var content io.Reader = response.Body
+	if len(crawler.hooks.OnResponse) > 0 {
+		// The hooks get the whole body, so it's read before parsing
+		data, err := ioutil.ReadAll(response.Body)
+		res.DownloadTime = time.Since(start)
+		crawler.hooks.response(crawler.ctx, response, data, err)
+		if err != nil {
+			res.ContentLength = int64(len(data))
+			res.setError(newCrawlError(address, err))
+			return []SearchResult{res}, nil
+		}
+		content = bytes.NewReader(data)
+	}
+	// parse the newly received html
+	body := &countingReader{r: content}
+	doc, err := links.ParseDocument(body)
+	var crawlErr *CrawlError
+	if err != nil {
+		crawlErr = &CrawlError{URL: address, Category: CategoryParse, Err: err}
+	} else if _, err = io.Copy(ioutil.Discard, body); err != nil {
+		// The parser might stop before the end of the page, the rest still counts for the download time
+		crawlErr = newCrawlError(address, err)
+	}
+	if res.DownloadTime == 0 {
+		res.DownloadTime = time.Since(start)
+	}
+	res.ContentLength = body.n
+	if crawlErr != nil {
+		res.setError(crawlErr)
+		return []SearchResult{res}, nil
+	}
+	// send the successful search result to the output
+	res.Meta = doc.Meta()
+	if crawler.collectImages {
+		res.Images = crawler.findImages(doc, url)
+	}
+	if crawler.collectVideos {
+		res.Videos = findVideos(doc, url)
+	}
+	if crawler.collectAlternates {
+		res.Alternates = doc.Alternates()
+		for i := range res.Alternates {
+			res.Alternates[i].URL = *url.ResolveReference(&res.Alternates[i].URL)
+		}
+	}
+	if crawler.collectAssets {
+		res.Assets = findAssets(doc, url)
+	}
+	if crawler.collectAnchors {
+		res.Anchors = doc.Anchors()
+	}
+
+	// Links are gathered before sending the result so that it carries the outgoing edges of the page
+	pageLinks := make([]links.Link, 0)
+	parseErrors := make([]links.LinkParseError, 0)
+	linksChan, errChan := doc.Links()
+	for linksChan != nil || errChan != nil {
+		// Both channels are drained to the end so the goroutine walking the document can exit
+		select {
+		case link, ok := <-linksChan:
+			if !ok {
+				linksChan = nil
+				break
+			}
+			pageLinks = append(pageLinks, link)
+		case e, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				break
+			}
+			parseErrors = append(parseErrors, e)
+		}
+	}
+	found := make([]task, 0)
+
+	// Alternates are often not linked from the pages, so they are visited the same way as links
+	for i, a := range res.Alternates {
+		next, ok := crawler.hooks.linkDiscovered(crawler.ctx, task{url: a.URL, hops: hopsCount + 1, source: SourceAlternate, referrer: address})
+		if !ok {
+			continue
+		}
+		res.Alternates[i].URL = next.url
+		found = crawler.follow(found, next)
+	}
+
+	// Links are followed by their URLs resolved against the page, since relative links on nested pages don't point to the root.
+	// The result holds the links rewritten by the hooks, so it shows the pages the crawl actually went to
+	res.Links = make([]links.Link, len(pageLinks))
+	for i, link := range pageLinks {
+		link.URL = *url.ResolveReference(&link.URL)
+		if next, ok := crawler.hooks.linkDiscovered(crawler.ctx, task{url: link.URL, hops: hopsCount + 1, source: SourceLink, referrer: address}); ok {
+			link.URL = next.url
+			if crawler.collectAnchors {
+				// The targets of links with fragments have to be fetched to check their anchors
+				next.url.Fragment = ""
+			}
+			found = crawler.follow(found, next)
+		}
+		res.Links[i] = link
+		if (link.URL.Scheme == "http" || link.URL.Scheme == "https") && !crawler.scopeFunc(link.URL) {
+			res.OutboundLinks = append(res.OutboundLinks, link)
+		}
+	}
+	results := []SearchResult{res}
+	for _, e := range parseErrors {
+		errRes := SearchResult{
+			Addr:     address,
+			Hops:     hopsCount,
+			Status:   res.Status,
+			Source:   source,
+			Referrer: referrer,
+			FinalURL: res.FinalURL,
+			// The page itself is loaded fine, only the link is broken
+			ContentType:   res.ContentType,
+			ContentLength: res.ContentLength,
+		}
+		errRes.setError(newCrawlError(address, e))
+		results = append(results, errRes)
+	}
+	return results, found
+}
+
+// pickHeaders returns the response headers of interest
+func (crawler *linkCrawler) pickHeaders(header http.Header) http.Header {
+	picked := make(http.Header)
+	for _, name := range crawler.headers {
+		if values := header.Values(name); len(values) > 0 {
+			picked[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return picked
+}
+
+// findImages returns the images of the page hosted on the crawled website with URLs resolved against the page URL
+func (crawler *linkCrawler) findImages(doc *links.Document, pageURL url.URL) []links.Image {
+	images := make([]links.Image, 0)
+	for _, img := range doc.Images() {
+		img.URL = *pageURL.ResolveReference(&img.URL)
+		if crawler.scopeFunc(img.URL) {
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// findAssets returns the assets of the page served over HTTP with URLs resolved against the page URL.
+// Assets are not filtered by host since they are often served by CDNs
+func findAssets(doc *links.Document, pageURL url.URL) []links.Asset {
+	assets := make([]links.Asset, 0)
+	seen := make(map[string]bool)
+	for _, a := range doc.Assets() {
+		a.URL = *pageURL.ResolveReference(&a.URL)
+		if a.URL.Scheme != "http" && a.URL.Scheme != "https" || seen[a.URL.String()] {
+			continue
+		}
+		seen[a.URL.String()] = true
+		assets = append(assets, a)
+	}
+	return assets
+}
+
+// findVideos returns the videos of the page with URLs resolved against the page URL.
+// Videos are not filtered by host since they are usually served by video hostings
+func findVideos(doc *links.Document, pageURL url.URL) []links.Video {
+	videos := doc.Videos()
+	resolve := func(u *url.URL) {
+		if u.String() != "" {
+			*u = *pageURL.ResolveReference(u)
+		}
+	}
+	for i := range videos {
+		resolve(&videos[i].ThumbnailURL)
+		resolve(&videos[i].ContentURL)
+		resolve(&videos[i].PlayerURL)
+	}
+	return videos
+}
+
+// CrawlOptions is a structure to set up the behavior of crawler
+type CrawlOptions struct {
+	MaxRoutines       uint
+	SearchConfig      SearchConfig
+	CollectImages     bool
+	CollectVideos     bool
+	CollectAlternates bool
+	CollectAssets     bool
+	CollectAnchors    bool
+	ResponseHeaders   []string
+	CaptureBody       int64
+	DebugDir          string
+	Deterministic     bool
+	MaxDepth          int
+	MaxPages          uint
+	Score             ScoreFunc
+	SitemapSeeds      []string
+	Fetcher           Fetcher
+	Hooks             Hooks
+}
+
+// Option is a function that configures the crawler
+type Option func(*CrawlOptions)
+
+// DefaultMaxRoutines is the number of pages crawled at the same time unless OptionMaxRoutines is given
+const DefaultMaxRoutines = 16
+
+// OptionMaxRoutines sets the number of workers crawling pages at the same time. If the value is 0, DefaultMaxRoutines workers are started.
+// Note that 0 used to mean a goroutine for every found page, the number of goroutines is always limited now
+// Default value is DefaultMaxRoutines
+func OptionMaxRoutines(num uint) Option {
+	return func(co *CrawlOptions) {
+		co.MaxRoutines = num
+	}
+}
+
+// OptionSearchIncludeSubdomains allows crawler to include links with subdomains
+// For example, if the initial hostname is example.com, crawler with this option turned on will visit the links on domains foo.example.com and/or bar.example.com
+func OptionSearchIncludeSubdomains() Option {
+	return func(co *CrawlOptions) {
+		co.SearchConfig.IncludeSubdomains = true
+	}
+}
+
+// OptionSearchIgnoreTopLevelDomain allows crawler to include links with different top level domains, which might be useful for international websites
+// For example, if the initial hostname is example.foo, crawler with this option turned on will visit the links on domains example.bar and/or example.baz
+func OptionSearchIgnoreTopLevelDomain() Option {
+	return func(co *CrawlOptions) {
+		co.SearchConfig.IgnoreTopLevelDomain = true
+	}
+}
+
+// OptionSearchAllowQuery allows crawler to include links with queries (by default, all links with query strings are ignored)
+func OptionSearchAllowQuery() Option {
+	return func(co *CrawlOptions) {
+		co.SearchConfig.IncludeLinksWithQuery = false
+	}
+}
+
+// OptionSearchIgnorePaths allows to specify patterns for link paths crawler should ignore
+func OptionSearchIgnorePaths(patterns ...string) Option {
+	return func(co *CrawlOptions) {
+		co.SearchConfig.ExcludedPaths = patterns
+	}
+}
+
+// OptionCollectImages makes crawler gather images found on each page (see SearchResult.Images)
+// Only images hosted on the crawled website according to the search options are collected
+func OptionCollectImages() Option {
+	return func(co *CrawlOptions) {
+		co.CollectImages = true
+	}
+}
+
+// OptionCollectVideos makes crawler gather videos embedded into each page (see SearchResult.Videos)
+// Videos are detected in <video> elements, iframes of known video hostings and JSON-LD VideoObject data
+func OptionCollectVideos() Option {
+	return func(co *CrawlOptions) {
+		co.CollectVideos = true
+	}
+}
+
+// OptionCollectAlternates makes crawler gather language alternates of each page given by <link rel="alternate" hreflang="..."> (see SearchResult.Alternates)
+func OptionCollectAlternates() Option {
+	return func(co *CrawlOptions) {
+		co.CollectAlternates = true
+	}
+}
+
+// OptionCollectAssets makes crawler gather images, scripts, stylesheets, fonts and media each page depends on (see SearchResult.Assets)
+// Assets are never crawled and don't appear among the found pages
+func OptionCollectAssets() Option {
+	return func(co *CrawlOptions) {
+		co.CollectAssets = true
+	}
+}
+
+// OptionCollectAnchors makes crawler gather the ids of elements of each page (see SearchResult.Anchors)
+// The links with fragments are followed as well with the fragment dropped, so their targets can be checked
+func OptionCollectAnchors() Option {
+	return func(co *CrawlOptions) {
+		co.CollectAnchors = true
+	}
+}
+
+// OptionResponseHeaders sets the names of response headers kept in search results (see SearchResult.Headers)
+// Default value is DefaultResponseHeaders
+func OptionResponseHeaders(names ...string) Option {
+	return func(co *CrawlOptions) {
+		co.ResponseHeaders = names
+	}
+}
+
+// OptionCaptureBody makes crawler keep up to maxBytes of response body of failed requests in FetchError.Body
+func OptionCaptureBody(maxBytes int64) Option {
+	return func(co *CrawlOptions) {
+		co.CaptureBody = maxBytes
+	}
+}
+
+// OptionDebugDir makes crawler write every failed HTTP exchange into directory dir as .http file (see FetchError.WriteHTTP).
+// The directory is created if it doesn't exist. Unless OptionCaptureBody is given, DefaultCaptureBody bytes of response body are captured
+func OptionDebugDir(dir string) Option {
+	return func(co *CrawlOptions) {
+		co.DebugDir = dir
+	}
+}
+
+// OptionDeterministic makes crawler go through the website breadth-first, one level of hops after another.
+// The pages of each level are visited in parallel, but the results are sent sorted by URL only when the whole level is done,
+// and the pages of the next level are taken in that order, so the crawls of the same website give the same results in the same order
+// as long as the website doesn't change. The results of one level are kept in memory till the level is done
+func OptionDeterministic() Option {
+	return func(co *CrawlOptions) {
+		co.Deterministic = true
+	}
+}
+
+// OptionMaxDepth limits the number of hops from the start page to the pages crawler visits. With depth 0 only the start page is visited
+// By default, the depth is not limited
+func OptionMaxDepth(depth uint) Option {
+	return func(co *CrawlOptions) {
+		co.MaxDepth = int(depth)
+	}
+}
+
+// OptionMaxPages limits the number of pages crawler visits. The limit is spent as the pages are taken from the queue, so the pages waiting there
+// keep competing on their scores till it's over, though a well rated page found late may still be left out.
+// Unless OptionDeterministic is given, the set of visited pages may differ from one crawl to another
+// Default value is 0, which means no limit
+func OptionMaxPages(num uint) Option {
+	return func(co *CrawlOptions) {
+		co.MaxPages = num
+	}
+}
+
+// OptionScore sets the function rating the pages waiting to be visited, the pages with higher scores are visited first.
+// With OptionDeterministic the pages are still visited level by level and only the order of admitting them within the page cap depends on the scores
+// Default value is NewScoreFunc(DefaultScoreWeights)
+func OptionScore(score ScoreFunc) Option {
+	return func(co *CrawlOptions) {
+		co.Score = score
+	}
+}
+
+// OptionSitemapSeeds adds the URLs listed in the sitemap of the website to the pages to visit, along with the ones found by following links.
+// The seeds are counted one hop away from the start page, and the URLs that don't pass the search options are ignored
+func OptionSitemapSeeds(urls ...string) Option {
+	return func(co *CrawlOptions) {
+		co.SitemapSeeds = append(co.SitemapSeeds, urls...)
+	}
+}
+
+// OptionFetcher sets the Fetcher crawler loads pages with. OptionCaptureBody has no effect on it
+// Default value is NewHTTPFetcher with the body capture set by OptionCaptureBody
+func OptionFetcher(f Fetcher) Option {
+	return func(co *CrawlOptions) {
+		co.Fetcher = f
+	}
+}
+
+// OptionOnRequest adds the hook run before every page is fetched, it may set the headers of the request (see Hooks for the order of hooks).
+// The headers reach a custom Fetcher only through RequestHeader(ctx)
+func OptionOnRequest(hook RequestHook) Option {
+	return func(co *CrawlOptions) {
+		co.Hooks.OnRequest = append(co.Hooks.OnRequest, hook)
+	}
+}
+
+// OptionOnResponse adds the hook run for every page fetched, including failed responses and requests.
+// With these hooks the bodies of pages are kept in memory till they are parsed
+func OptionOnResponse(hook ResponseHook) Option {
+	return func(co *CrawlOptions) {
+		co.Hooks.OnResponse = append(co.Hooks.OnResponse, hook)
+	}
+}
+
+// OptionOnLinkDiscovered adds the hook run for every page found on the crawled pages, it may skip the page or change its URL
+func OptionOnLinkDiscovered(hook LinkHook) Option {
+	return func(co *CrawlOptions) {
+		co.Hooks.OnLinkDiscovered = append(co.Hooks.OnLinkDiscovered, hook)
+	}
+}
+
+// OptionOnResult adds the hook run for every search result before it's sent to the output
+func OptionOnResult(hook ResultHook) Option {
+	return func(co *CrawlOptions) {
+		co.Hooks.OnResult = append(co.Hooks.OnResult, hook)
+	}
+}
+
+// OptionOnFinish adds the hook run once the crawl is complete or cancelled, before the output channel is closed
+func OptionOnFinish(hook FinishHook) Option {
+	return func(co *CrawlOptions) {
+		co.Hooks.OnFinish = append(co.Hooks.OnFinish, hook)
+	}
+}
+
+// Crawl initiates website crawling to find all internal links
+// initialAddr must be full URL string with protocol without path, query string or anchor
+// options is a slice of functional options from this package (functions starting with Option*) to configure the behavior of the crawler
+// The returned channel is closed when the crawl is complete. Cancelling ctx aborts the requests in progress and closes the channel as soon as
+// all goroutines of the crawler exit. No results are sent after cancellation, so the caller doesn't have to drain the channel
+func Crawl(ctx context.Context, initialAddr string, options ...Option) (<-chan SearchResult, error) {
+	opt := CrawlOptions{
+		ResponseHeaders: DefaultResponseHeaders,
+		MaxDepth:        -1,
+		Score:           NewScoreFunc(DefaultScoreWeights),
+	}
+	for _, o := range options {
+		o(&opt)
+	}
+
+	initURL, err := url.Parse(initialAddr)
+	if err != nil {
+		return nil, err
+	}
+	if initURL.Hostname() == "" {
+		return nil, errors.New("Hostname is empty")
+	}
+
+	var debug *debugWriter
+	if opt.DebugDir != "" {
+		if debug, err = newDebugWriter(opt.DebugDir); err != nil {
+			return nil, err
+		}
+		if opt.CaptureBody == 0 {
+			opt.CaptureBody = DefaultCaptureBody
+		}
+	}
+
+	fetcher := opt.Fetcher
+	if fetcher == nil {
+		fetcher = NewHTTPFetcher(opt.CaptureBody)
+	}
+	workers := opt.MaxRoutines
+	if workers == 0 {
+		workers = DefaultMaxRoutines
+	}
+	crawler := &linkCrawler{
+		initURL:           initURL,
+		fetcher:           fetcher,
+		hooks:             opt.Hooks,
+		filterFunc:        makeFilterFunc(opt.SearchConfig, *initURL),
+		scopeFunc:         makeScopeFunc(opt.SearchConfig, *initURL),
+		collectImages:     opt.CollectImages,
+		collectVideos:     opt.CollectVideos,
+		collectAlternates: opt.CollectAlternates,
+		collectAssets:     opt.CollectAssets,
+		collectAnchors:    opt.CollectAnchors,
+		headers:           opt.ResponseHeaders,
+		debug:             debug,
+		history:           newHistory(),
+		maxDepth:          opt.MaxDepth,
+		maxPages:          opt.MaxPages,
+		score:             opt.Score,
+		frontier:          newFrontier(opt.Score, opt.MaxPages),
+		outChan:           make(chan SearchResult),
+		ctx:               ctx,
+	}
+
+	start := task{url: *initURL, hops: 0, source: SourceStart}
+	seeds := make([]task, 0, len(opt.SitemapSeeds))
+	for _, addr := range opt.SitemapSeeds {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, err
+		}
+		if crawler.filterFunc(*u) {
+			seeds = append(seeds, task{url: *u, hops: 1, source: SourceSitemap})
+		}
+	}
+	crawler.admit(start)
+	if opt.Deterministic {
+		go crawler.crawlLevels(start, seeds, workers)
+		return crawler.outChan, nil
+	}
+
+	// The pages are kept in the frontier till one of the fixed number of workers takes them,
+	// so the number of goroutines doesn't grow with the size of the website
+	crawler.frontier.push(start)
+	for _, t := range seeds {
+		if crawler.admit(t) {
+			crawler.frontier.push(t)
+		}
+	}
+	wg := &sync.WaitGroup{}
+	for i := uint(0); i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			crawler.work()
+		}()
+	}
+	finished := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			crawler.frontier.close()
+		case <-finished:
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(finished)
+		crawler.finish()
+	}()
+	return crawler.outChan, nil
+}
@@ -0,0 +1,382 @@
+package linkcrawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSite serves the pages linking to the given paths, the rest of paths are not found. It records the requests it gets
+type testSite struct {
+	*httptest.Server
+	mut       sync.Mutex
+	requested []string
+	headers   map[string]http.Header
+}
+
+func newTestSite(t *testing.T, pages map[string][]string) *testSite {
+	t.Helper()
+	site := &testSite{headers: make(map[string]http.Header)}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mut.Lock()
+		site.requested = append(site.requested, r.URL.Path)
+		site.headers[r.URL.Path] = r.Header
+		site.mut.Unlock()
+
+		links, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><head><title>%s</title></head><body>", r.URL.Path)
+		for _, link := range links {
+			fmt.Fprintf(w, `<a href="%s">%s</a>`, link, link)
+		}
+		fmt.Fprint(w, "</body></html>")
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+// requests returns the paths requested so far in the order of requests
+func (site *testSite) requests() []string {
+	site.mut.Lock()
+	defer site.mut.Unlock()
+	return append([]string(nil), site.requested...)
+}
+
+// treeSite is the website with two levels of pages below the start one
+var treeSite = map[string][]string{
+	"/":   {"/b", "/a"},
+	"/a":  {"/a2", "/a1", "/"},
+	"/b":  {"/b1", "/a1"},
+	"/a1": {},
+	"/a2": {},
+	"/b1": {"/missing"},
+}
+
+// pathOf returns the path of the crawled page, the start page is "/"
+func pathOf(t *testing.T, addr string) string {
+	t.Helper()
+	u, err := url.Parse(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// crawlPaths crawls the website and returns the paths of the results in the order they are sent
+func crawlPaths(t *testing.T, site *testSite, options ...Option) []string {
+	t.Helper()
+	results, err := Crawl(context.Background(), site.URL+"/", options...)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	paths := make([]string, 0)
+	for res := range results {
+		paths = append(paths, pathOf(t, res.Addr))
+	}
+	return paths
+}
+
+func sortedCopy(s []string) []string {
+	sorted := append([]string(nil), s...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCrawlFindsAllPages(t *testing.T) {
+	site := newTestSite(t, treeSite)
+	want := []string{"/", "/a", "/a1", "/a2", "/b", "/b1", "/missing"}
+	for _, deterministic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("deterministic %t", deterministic), func(t *testing.T) {
+			options := []Option{}
+			if deterministic {
+				options = append(options, OptionDeterministic())
+			}
+			got := sortedCopy(crawlPaths(t, site, options...))
+			if !equalStrings(got, want) {
+				t.Errorf("crawled %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCrawlDeterministicOrder(t *testing.T) {
+	site := newTestSite(t, treeSite)
+	// Pages go level by level, the ones of the same level in the order of their URLs
+	want := []string{"/", "/a", "/b", "/a1", "/a2", "/b1", "/missing"}
+	for i := 0; i < 3; i++ {
+		got := crawlPaths(t, site, OptionDeterministic(), OptionMaxRoutines(4))
+		if !equalStrings(got, want) {
+			t.Fatalf("run %d crawled %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestCrawlScoreOrder(t *testing.T) {
+	site := newTestSite(t, map[string][]string{
+		"/":       {"/x", "/y", "/docs/z"},
+		"/x":      {},
+		"/y":      {"/docs/w"},
+		"/docs/z": {"/y"},
+		"/docs/w": {},
+	})
+	weights := DefaultScoreWeights
+	weights.Patterns = map[string]float64{"/docs/": 100}
+	score := OptionScore(NewScoreFunc(weights))
+	tests := []struct {
+		name    string
+		options []Option
+		want    []string
+	}{
+		// A single worker visits the pages strictly in the order of their scores, /y has more links than /x
+		{"single worker", []Option{OptionMaxRoutines(1)}, []string{"/", "/docs/z", "/y", "/docs/w", "/x"}},
+		{"deterministic", []Option{OptionDeterministic()}, []string{"/", "/docs/z", "/x", "/y", "/docs/w"}},
+		// The page cap is spent on the best rated pages
+		{"single worker with page cap", []Option{OptionMaxRoutines(1), OptionMaxPages(3)}, []string{"/", "/docs/z", "/y"}},
+		// The level is chosen before the links found on it are counted, so /y doesn't get ahead of /x
+		{"deterministic with page cap", []Option{OptionDeterministic(), OptionMaxPages(3)}, []string{"/", "/docs/z", "/x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := crawlPaths(t, site, append(tt.options, score)...)
+			if !equalStrings(got, tt.want) {
+				t.Errorf("crawled %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlLimits(t *testing.T) {
+	site := newTestSite(t, treeSite)
+	tests := []struct {
+		name    string
+		options []Option
+		want    []string
+	}{
+		{"depth 0", []Option{OptionMaxDepth(0)}, []string{"/"}},
+		{"depth 1", []Option{OptionMaxDepth(1)}, []string{"/", "/a", "/b"}},
+		{"depth 2", []Option{OptionMaxDepth(2)}, []string{"/", "/a", "/a1", "/a2", "/b", "/b1"}},
+		{"1 page", []Option{OptionMaxPages(1)}, []string{"/"}},
+		{"3 pages", []Option{OptionMaxPages(3)}, []string{"/", "/a", "/b"}},
+		{"depth and pages", []Option{OptionMaxDepth(2), OptionMaxPages(100)}, []string{"/", "/a", "/a1", "/a2", "/b", "/b1"}},
+	}
+	for _, tt := range tests {
+		for _, deterministic := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s deterministic %t", tt.name, deterministic), func(t *testing.T) {
+				// A single worker keeps the order of pages of the same score, so the page cap leaves out the same pages every time
+				options := append([]Option{OptionMaxRoutines(1)}, tt.options...)
+				if deterministic {
+					options = append(options, OptionDeterministic())
+				}
+				got := sortedCopy(crawlPaths(t, site, options...))
+				if !equalStrings(got, tt.want) {
+					t.Errorf("crawled %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCrawlResults(t *testing.T) {
+	site := newTestSite(t, treeSite)
+	results, err := Crawl(context.Background(), site.URL+"/")
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	byPath := make(map[string]SearchResult)
+	for res := range results {
+		byPath[pathOf(t, res.Addr)] = res
+	}
+
+	start := byPath["/"]
+	if start.Error != nil || start.Status != http.StatusOK || start.Hops != 0 || start.Source != SourceStart {
+		t.Errorf("start page result is %+v", start)
+	}
+	if start.Meta.Title != "/" {
+		t.Errorf("start page title is %q", start.Meta.Title)
+	}
+	if len(start.Links) != 2 || start.Links[0].URL.String() != site.URL+"/b" {
+		t.Errorf("start page links are %v", start.Links)
+	}
+	if a1 := byPath["/a1"]; a1.Hops != 2 || a1.Source != SourceLink {
+		t.Errorf("/a1 result is %+v", a1)
+	}
+	missing := byPath["/missing"]
+	if missing.Status != http.StatusNotFound || missing.Category != CategoryClientError || !errors.Is(missing.Error, ErrClientError) {
+		t.Errorf("missing page result is %+v", missing)
+	}
+	if missing.Referrer != site.URL+"/b1" {
+		t.Errorf("missing page referrer is %q", missing.Referrer)
+	}
+}
+
+// endlessSite serves the pages linking to the next two pages, so it never ends
+func endlessSite(t *testing.T) *testSite {
+	t.Helper()
+	site := &testSite{headers: make(map[string]http.Header)}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><body><a href="/%d">next</a><a href="/%d">next</a></body></html>`, 2*n+1, 2*n+2)
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+func TestCrawlCancel(t *testing.T) {
+	site := endlessSite(t)
+	for _, deterministic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("deterministic %t", deterministic), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			finished := make(chan error, 1)
+			options := []Option{OptionOnFinish(func(ctx context.Context, err error) {
+				finished <- err
+			})}
+			if deterministic {
+				options = append(options, OptionDeterministic())
+			}
+			results, err := Crawl(ctx, site.URL+"/", options...)
+			if err != nil {
+				t.Fatalf("Crawl failed: %v", err)
+			}
+
+			received := 0
+			timeout := time.After(5 * time.Second)
+			for open := true; open; {
+				select {
+				case _, open = <-results:
+					if !open {
+						break
+					}
+					received++
+					if received == 20 {
+						cancel()
+					}
+				case <-timeout:
+					t.Fatal("the output is not closed after cancelling the crawl")
+				}
+			}
+			if received < 20 {
+				t.Errorf("received %d results before the output was closed", received)
+			}
+			if err := <-finished; err != context.Canceled {
+				t.Errorf("OnFinish got %v, want context.Canceled", err)
+			}
+		})
+	}
+}
+
+func TestCustomFetcherError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The fetcher returns the same error without optional fields for every page
+	notFound := &FetchError{Code: http.StatusNotFound, Status: "404 Not Found"}
+	fetcher := FetcherFunc(func(ctx context.Context, addr string) (*http.Response, error) {
+		return nil, notFound
+	})
+	var responseURL string
+	results, err := Crawl(context.Background(), "https://example.com/",
+		OptionFetcher(fetcher),
+		OptionDebugDir(dir),
+		OptionOnResponse(func(ctx context.Context, res *http.Response, body []byte, err error) {
+			responseURL = res.Request.URL.String()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	count := 0
+	for res := range results {
+		count++
+		if res.Status != http.StatusNotFound || res.Category != CategoryClientError {
+			t.Errorf("result is %+v", res)
+		}
+		if res.FinalURL != "https://example.com/" {
+			t.Errorf("final URL is %q", res.FinalURL)
+		}
+		if msg := res.Error.Error(); !strings.Contains(msg, "https://example.com/") {
+			t.Errorf("error %q doesn't mention the page", msg)
+		}
+	}
+	if count != 1 {
+		t.Errorf("got %d results, want 1", count)
+	}
+	if responseURL != "https://example.com/" {
+		t.Errorf("OnResponse got the response from %q", responseURL)
+	}
+	if len(notFound.RequestURLs) != 0 {
+		t.Errorf("the error of the fetcher is changed: %v", notFound.RequestURLs)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "0001-example.com.http" {
+		t.Errorf("debug directory holds %v", files)
+	}
+}
+
+func TestDebugDirError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The directory disappears once the crawl is started, so the failure can't be written
+	fetcher := FetcherFunc(func(ctx context.Context, addr string) (*http.Response, error) {
+		os.RemoveAll(dir)
+		return nil, &FetchError{Code: http.StatusInternalServerError, Status: "500 Internal Server Error"}
+	})
+	results, err := Crawl(context.Background(), "https://example.com/", OptionFetcher(fetcher), OptionDebugDir(dir))
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	count := 0
+	for res := range results {
+		count++
+		if res.Category != CategoryServerError {
+			t.Errorf("result is %+v", res)
+		}
+		if !os.IsNotExist(res.DebugError) {
+			t.Errorf("debug error is %v", res.DebugError)
+		}
+	}
+	if count != 1 {
+		t.Errorf("got %d results, want 1", count)
+	}
+}
@@ -0,0 +1,56 @@
+package linkcrawler
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Candidate is a page waiting in the frontier to be visited, it's rated with ScoreFunc
+type Candidate struct {
+	URL  url.URL
+	Hops int
+	// Source tells how the page was discovered
+	Source Source
+	// Referrer is the URL of the page the link was first found on
+	Referrer string
+	// Inbound is the number of links to the page found so far
+	Inbound int
+}
+
+// ScoreFunc rates the page waiting in the frontier, the pages with higher scores are visited first.
+// The page is rated again every time a new link to it is found. Pages with equal scores are visited in the order they were found
+type ScoreFunc func(Candidate) float64
+
+// ScoreWeights configures the ScoreFunc made by NewScoreFunc. The score of the page is the sum of the weights applying to it
+type ScoreWeights struct {
+	// Depth is added for every hop from the start page, it's usually negative so the pages closer to the start page go first
+	Depth float64
+	// Inbound is added for every link to the page found so far
+	Inbound float64
+	// Patterns are added for the pages with URLs containing the key
+	Patterns map[string]float64
+	// Sources are added for the pages discovered the given way
+	Sources map[Source]float64
+}
+
+// DefaultScoreWeights visit the sitemap seeds first and the rest of pages by the number of hops from the start page,
+// giving a hop for every 10 links to the page
+var DefaultScoreWeights = ScoreWeights{
+	Depth:   -10,
+	Inbound: 1,
+	Sources: map[Source]float64{SourceSitemap: 1000},
+}
+
+// NewScoreFunc makes ScoreFunc rating pages with the given weights
+func NewScoreFunc(weights ScoreWeights) ScoreFunc {
+	return func(c Candidate) float64 {
+		score := weights.Depth*float64(c.Hops) + weights.Inbound*float64(c.Inbound) + weights.Sources[c.Source]
+		addr := c.URL.String()
+		for pattern, weight := range weights.Patterns {
+			if strings.Contains(addr, pattern) {
+				score += weight
+			}
+		}
+		return score
+	}
+}
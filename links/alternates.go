@@ -0,0 +1,46 @@
+package links
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Alternate is a reference to the version of the page in another language given by <link rel="alternate" hreflang="...">
+type Alternate struct {
+	// Lang is the value of hreflang attribute like "en", "pt-BR" or "x-default"
+	Lang string
+	URL  url.URL
+}
+
+// hasRel checks if the space-separated rel attribute of the node has given value
+func hasRel(node *html.Node, rel string) bool {
+	for _, r := range strings.Fields(getAttr(node, "rel")) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Alternates finds all language alternates of the page. Links failing to parse are skipped
+func (doc *Document) Alternates() []Alternate {
+	alternates := make([]Alternate, 0)
+	walk(doc.root, func(node *html.Node) {
+		if node.Data != "link" || !hasRel(node, "alternate") {
+			return
+		}
+		lang := strings.TrimSpace(getAttr(node, "hreflang"))
+		href := strings.TrimSpace(getAttr(node, "href"))
+		if lang == "" || href == "" {
+			return
+		}
+		u, err := url.Parse(href)
+		if err != nil {
+			return
+		}
+		alternates = append(alternates, Alternate{Lang: lang, URL: *u})
+	})
+	return alternates
+}
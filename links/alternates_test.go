@@ -0,0 +1,47 @@
+package links
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlternates(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []Alternate
+	}{
+		{"none", `<link rel="stylesheet" href="/a.css">`, []Alternate{}},
+		{"alternates", `<link rel="alternate" hreflang="en" href="/"><link rel="alternate" hreflang="pt-BR" href="/br/">`, []Alternate{
+			{Lang: "en", URL: parseURL("/")},
+			{Lang: "pt-BR", URL: parseURL("/br/")},
+		}},
+		{"rel with other values", `<link rel="Alternate nofollow" hreflang=" x-default " href=" https://example.com/ ">`, []Alternate{
+			{Lang: "x-default", URL: parseURL("https://example.com/")},
+		}},
+		// Alternates without hreflang are other formats of the page, like feeds
+		{"no hreflang", `<link rel="alternate" type="application/rss+xml" href="/feed">`, []Alternate{}},
+		{"no href", `<link rel="alternate" hreflang="de">`, []Alternate{}},
+		{"not a link", `<a rel="alternate" hreflang="de" href="/de/">Deutsch</a>`, []Alternate{}},
+		{"malformed", `<link rel="alternate" hreflang="de" href="%zz"><link rel="alternate" hreflang="fr" href="/fr/">`, []Alternate{
+			{Lang: "fr", URL: parseURL("/fr/")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("ParseDocument failed: %v", err)
+			}
+			got := doc.Alternates()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got alternates %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Lang != tt.want[i].Lang || got[i].URL.String() != tt.want[i].URL.String() {
+					t.Errorf("alternate %d is %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package links
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// AssetKind is the kind of resource the page depends on
+type AssetKind string
+
+const (
+	AssetImage      AssetKind = "image"
+	AssetScript     AssetKind = "script"
+	AssetStylesheet AssetKind = "stylesheet"
+	AssetFont       AssetKind = "font"
+	// AssetMedia is the video or audio source
+	AssetMedia AssetKind = "media"
+)
+
+// Asset is a reference to the resource the page needs to be displayed properly
+type Asset struct {
+	URL  url.URL
+	Kind AssetKind
+}
+
+// cssURLRegexp matches url() references of CSS with optional quotes
+var cssURLRegexp = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\)`)
+
+var fontExtensions = map[string]bool{".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true}
+
+// cssAssets returns the URLs referenced with url() in CSS code. Fonts are told by their file extensions, anything else is taken for an image
+func cssAssets(css string) []Asset {
+	assets := make([]Asset, 0)
+	for _, m := range cssURLRegexp.FindAllStringSubmatch(css, -1) {
+		src := m[1] + m[2] + m[3]
+		u, err := url.Parse(strings.TrimSpace(src))
+		if err != nil {
+			continue
+		}
+		kind := AssetImage
+		if fontExtensions[strings.ToLower(path.Ext(u.Path))] {
+			kind = AssetFont
+		}
+		assets = append(assets, Asset{URL: *u, Kind: kind})
+	}
+	return assets
+}
+
+// preloadKinds maps the values of "as" attribute of preloaded resources to asset kinds
+var preloadKinds = map[string]AssetKind{
+	"image":  AssetImage,
+	"script": AssetScript,
+	"style":  AssetStylesheet,
+	"font":   AssetFont,
+	"audio":  AssetMedia,
+	"video":  AssetMedia,
+}
+
+// Assets finds all resources the page depends on: images (including srcset and icons), scripts, stylesheets, preloaded fonts,
+// video and audio sources, and url() references of inline styles and <style> elements.
+// Inline resources (data URIs) and URLs failing to parse are skipped, each asset is returned only once
+func (doc *Document) Assets() []Asset {
+	assets := make([]Asset, 0)
+	seen := make(map[string]bool)
+	add := func(src string, kind AssetKind) {
+		src = strings.TrimSpace(src)
+		if src == "" || strings.HasPrefix(src, "data:") || seen[src] {
+			return
+		}
+		u, err := url.Parse(src)
+		if err != nil {
+			return
+		}
+		seen[src] = true
+		assets = append(assets, Asset{URL: *u, Kind: kind})
+	}
+	addSrcset := func(srcset string, kind AssetKind) {
+		for _, src := range parseSrcset(srcset) {
+			add(src, kind)
+		}
+	}
+
+	walk(doc.root, func(node *html.Node) {
+		switch node.Data {
+		case "img":
+			add(getAttr(node, "src"), AssetImage)
+			addSrcset(getAttr(node, "srcset"), AssetImage)
+		case "script":
+			add(getAttr(node, "src"), AssetScript)
+		case "link":
+			rel := strings.Fields(strings.ToLower(getAttr(node, "rel")))
+			for _, r := range rel {
+				switch r {
+				case "stylesheet":
+					add(getAttr(node, "href"), AssetStylesheet)
+				case "icon", "apple-touch-icon":
+					add(getAttr(node, "href"), AssetImage)
+				case "preload", "modulepreload":
+					kind, ok := preloadKinds[strings.ToLower(getAttr(node, "as"))]
+					if r == "modulepreload" {
+						kind, ok = AssetScript, true
+					}
+					if ok {
+						add(getAttr(node, "href"), kind)
+					}
+				}
+			}
+		case "source":
+			kind := AssetMedia
+			if node.Parent != nil && node.Parent.Data == "picture" {
+				kind = AssetImage
+			}
+			add(getAttr(node, "src"), kind)
+			addSrcset(getAttr(node, "srcset"), kind)
+		case "video":
+			add(getAttr(node, "src"), AssetMedia)
+			add(getAttr(node, "poster"), AssetImage)
+		case "audio":
+			add(getAttr(node, "src"), AssetMedia)
+		case "style":
+			if node.FirstChild != nil {
+				for _, a := range cssAssets(node.FirstChild.Data) {
+					add(a.URL.String(), a.Kind)
+				}
+			}
+		}
+		if style := getAttr(node, "style"); style != "" {
+			for _, a := range cssAssets(style) {
+				add(a.URL.String(), a.Kind)
+			}
+		}
+	})
+	return assets
+}
@@ -0,0 +1,92 @@
+package links
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSSAssets(t *testing.T) {
+	tests := []struct {
+		name string
+		css  string
+		want []Asset
+	}{
+		{"none", `color: red`, []Asset{}},
+		{"unquoted", `background: url(/bg.png)`, []Asset{{URL: parseURL("/bg.png"), Kind: AssetImage}}},
+		{"quoted", `background: url( "/a b.png" ), url('/c.png')`, []Asset{
+			{URL: parseURL("/a b.png"), Kind: AssetImage},
+			{URL: parseURL("/c.png"), Kind: AssetImage},
+		}},
+		{"font", `@font-face { src: url(/f.WOFF2) format("woff2"), url("/f.ttf?v=1") }`, []Asset{
+			{URL: parseURL("/f.WOFF2"), Kind: AssetFont},
+			{URL: parseURL("/f.ttf?v=1"), Kind: AssetFont},
+		}},
+		{"malformed", `background: url(%zz), url(/ok.png)`, []Asset{{URL: parseURL("/ok.png"), Kind: AssetImage}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkAssets(t, cssAssets(tt.css), tt.want)
+		})
+	}
+}
+
+func TestAssets(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []Asset
+	}{
+		{"none", `<a href="/page">page</a>`, []Asset{}},
+		{"image", `<img src="/a.png" srcset="/a.png 1x, /a@2x.png 2x">`, []Asset{
+			{URL: parseURL("/a.png"), Kind: AssetImage},
+			{URL: parseURL("/a@2x.png"), Kind: AssetImage},
+		}},
+		{"script", `<script src="/app.js"></script><script>inline()</script>`, []Asset{{URL: parseURL("/app.js"), Kind: AssetScript}}},
+		{"links", `<link rel="stylesheet" href="/a.css"><link rel="icon" href="/favicon.ico"><link rel="canonical" href="/">`, []Asset{
+			{URL: parseURL("/a.css"), Kind: AssetStylesheet},
+			{URL: parseURL("/favicon.ico"), Kind: AssetImage},
+		}},
+		{"preload", `<link rel="preload" as="font" href="/f.woff2"><link rel="preload" as="fetch" href="/data.json"><link rel="modulepreload" href="/m.js">`, []Asset{
+			{URL: parseURL("/f.woff2"), Kind: AssetFont},
+			{URL: parseURL("/m.js"), Kind: AssetScript},
+		}},
+		{"media", `<video src="/v.mp4" poster="/p.jpg"><source src="/v.webm"></video><audio src="/a.mp3"></audio>`, []Asset{
+			{URL: parseURL("/v.mp4"), Kind: AssetMedia},
+			{URL: parseURL("/p.jpg"), Kind: AssetImage},
+			{URL: parseURL("/v.webm"), Kind: AssetMedia},
+			{URL: parseURL("/a.mp3"), Kind: AssetMedia},
+		}},
+		{"picture", `<picture><source srcset="/a.webp"><img src="/a.png"></picture>`, []Asset{
+			{URL: parseURL("/a.webp"), Kind: AssetImage},
+			{URL: parseURL("/a.png"), Kind: AssetImage},
+		}},
+		{"styles", `<style>body { background: url(/bg.png) }</style><div style="background: url('/d.png')"></div>`, []Asset{
+			{URL: parseURL("/bg.png"), Kind: AssetImage},
+			{URL: parseURL("/d.png"), Kind: AssetImage},
+		}},
+		{"skipped", `<img src="data:image/png;base64,AAAA"><img src="%zz"><img src="/a.png"><img src="/a.png">`, []Asset{
+			{URL: parseURL("/a.png"), Kind: AssetImage},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("ParseDocument failed: %v", err)
+			}
+			checkAssets(t, doc.Assets(), tt.want)
+		})
+	}
+}
+
+func checkAssets(t *testing.T, got, want []Asset) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got assets %+v, want %+v", got, want)
+	}
+	for i := range got {
+		if got[i].URL.String() != want[i].URL.String() || got[i].Kind != want[i].Kind {
+			t.Errorf("asset %d is %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
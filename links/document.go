@@ -0,0 +1,86 @@
+package links
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Document is a parsed HTML page which can be searched for different kinds of references
+type Document struct {
+	root *html.Node
+}
+
+// ParseDocument parses HTML page passed by reader
+func ParseDocument(reader io.Reader) (*Document, error) {
+	node, err := html.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{root: node}, nil
+}
+
+// Links finds all successfully parsed links in <a> tags through channel the same way FindLinks does
+func (doc *Document) Links() (<-chan Link, <-chan LinkParseError) {
+	oc := make(chan Link)
+	ec := make(chan LinkParseError)
+	go func() {
+		seekLinkNodes(doc.root, oc, ec)
+		close(oc)
+		close(ec)
+	}()
+
+	return oc, ec
+}
+
+// walk calls fn for every element node of the tree in document order
+func walk(node *html.Node, fn func(*html.Node)) {
+	if node.Type == html.ElementNode {
+		fn(node)
+	}
+	for c := node.FirstChild; c != nil && c.Type != html.ErrorNode; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// getAttr returns the value of node attribute with given key or empty string if it's not set
+func getAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the text of all descendants of the node with collapsed whitespace
+func textContent(node *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(node)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// Anchors returns the ids of all elements of the page and the names of <a> elements, which links can point to with URL fragment
+func (doc *Document) Anchors() []string {
+	anchors := make([]string, 0)
+	walk(doc.root, func(node *html.Node) {
+		if id := getAttr(node, "id"); id != "" {
+			anchors = append(anchors, id)
+		}
+		if name := getAttr(node, "name"); node.Data == "a" && name != "" {
+			anchors = append(anchors, name)
+		}
+	})
+	return anchors
+}
@@ -0,0 +1,32 @@
+package links
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnchors(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []string
+	}{
+		{"none", `<p>text</p>`, []string{}},
+		{"ids", `<h1 id="top">Title</h1><section id="about"><p id="more">text</p></section>`, []string{"top", "about", "more"}},
+		{"named anchors", `<a name="legacy"></a><a href="#legacy" id="link">link</a>`, []string{"legacy", "link"}},
+		// Only <a> elements can be pointed at by their names
+		{"other names", `<form name="search"><input name="q"></form>`, []string{}},
+		{"head", `<html id="root"><head><meta id="m"></head></html>`, []string{"root", "m"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("ParseDocument failed: %v", err)
+			}
+			if got := doc.Anchors(); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
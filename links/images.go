@@ -0,0 +1,101 @@
+package links
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Image is a reference to image found on the page
+type Image struct {
+	URL     url.URL
+	Title   string
+	Caption string
+}
+
+// parseSrcset returns the URLs of all image candidates listed in srcset attribute
+func parseSrcset(srcset string) []string {
+	srcs := make([]string, 0)
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			srcs = append(srcs, fields[0])
+		}
+	}
+	return srcs
+}
+
+// findCaption returns the text of <figcaption> of the figure the node belongs to
+func findCaption(node *html.Node) string {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if p.Type != html.ElementNode || p.Data != "figure" {
+			continue
+		}
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "figcaption" {
+				return textContent(c)
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// Images finds all images referenced by <img> tags (both src and srcset) and <source> tags inside <picture>.
+// Title is taken from title or alt attribute of the image and caption is taken from enclosing figure or alt attribute.
+// Inline images (data URIs) and URLs failing to parse are skipped, each image is returned only once
+func (doc *Document) Images() []Image {
+	images := make([]Image, 0)
+	seen := make(map[string]bool)
+	add := func(src string, img *html.Node) {
+		src = strings.TrimSpace(src)
+		if src == "" || strings.HasPrefix(src, "data:") || seen[src] {
+			return
+		}
+		u, err := url.Parse(src)
+		if err != nil {
+			return
+		}
+		seen[src] = true
+
+		image := Image{URL: *u}
+		if img != nil {
+			alt := strings.TrimSpace(getAttr(img, "alt"))
+			image.Title = strings.TrimSpace(getAttr(img, "title"))
+			if image.Title == "" {
+				image.Title = alt
+			}
+			image.Caption = findCaption(img)
+			if image.Caption == "" {
+				image.Caption = alt
+			}
+		}
+		images = append(images, image)
+	}
+
+	walk(doc.root, func(node *html.Node) {
+		switch node.Data {
+		case "img":
+			add(getAttr(node, "src"), node)
+			for _, src := range parseSrcset(getAttr(node, "srcset")) {
+				add(src, node)
+			}
+		case "source":
+			// <source> is also used by <video> and <audio>, only pictures are relevant here
+			if node.Parent == nil || node.Parent.Data != "picture" {
+				return
+			}
+			var img *html.Node
+			for c := node.Parent.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && c.Data == "img" {
+					img = c
+					break
+				}
+			}
+			for _, src := range parseSrcset(getAttr(node, "srcset")) {
+				add(src, img)
+			}
+		}
+	})
+	return images
+}
@@ -0,0 +1,71 @@
+package links
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		srcset string
+		want   []string
+	}{
+		{"", []string{}},
+		{"small.jpg", []string{"small.jpg"}},
+		{"small.jpg 480w, large.jpg 1080w", []string{"small.jpg", "large.jpg"}},
+		{" a.png 1x ,b.png 2x, ", []string{"a.png", "b.png"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.srcset, func(t *testing.T) {
+			got := parseSrcset(tt.srcset)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImages(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []Image
+	}{
+		{"none", `<p>text</p>`, []Image{}},
+		{"src", `<img src="/a.png">`, []Image{{URL: parseURL("/a.png")}}},
+		{"title", `<img src="/a.png" title="Logo" alt="Our logo">`, []Image{{URL: parseURL("/a.png"), Title: "Logo", Caption: "Our logo"}}},
+		{"alt", `<img src="/a.png" alt="Logo">`, []Image{{URL: parseURL("/a.png"), Title: "Logo", Caption: "Logo"}}},
+		{"srcset", `<img src="/a.png" srcset="/a.png 1x, /a@2x.png 2x">`, []Image{
+			{URL: parseURL("/a.png")},
+			{URL: parseURL("/a@2x.png")},
+		}},
+		{"figure", `<figure><img src="/a.png" alt="Logo"><figcaption> The  logo </figcaption></figure>`, []Image{
+			{URL: parseURL("/a.png"), Title: "Logo", Caption: "The logo"},
+		}},
+		{"picture", `<picture><source srcset="/a.webp"><img src="/a.png" alt="Logo"></picture>`, []Image{
+			{URL: parseURL("/a.webp"), Title: "Logo", Caption: "Logo"},
+			{URL: parseURL("/a.png"), Title: "Logo", Caption: "Logo"},
+		}},
+		{"video source", `<video><source src="/a.mp4" srcset="/a.webp"></video>`, []Image{}},
+		{"data URI", `<img src="data:image/png;base64,AAAA">`, []Image{}},
+		{"duplicate", `<img src="/a.png"><img src="/a.png" alt="Again">`, []Image{{URL: parseURL("/a.png")}}},
+		{"malformed", `<img src="%zz"><img src="/a.png">`, []Image{{URL: parseURL("/a.png")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("ParseDocument failed: %v", err)
+			}
+			got := doc.Images()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got images %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].URL.String() != tt.want[i].URL.String() || got[i].Title != tt.want[i].Title || got[i].Caption != tt.want[i].Caption {
+					t.Errorf("image %d is %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,3 +1,4 @@
+// Package links extracts links, metadata, images, videos, language alternates and assets from HTML documents
 package links
 
 import (
@@ -5,14 +6,18 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 
 	"golang.org/x/net/html"
 )
 
 // Link is a structure for holding named URLs
 type Link struct {
+	// Name is the anchor text of the link
 	Name string
 	URL  url.URL
+	// Rel holds the link types from rel attribute in lower case, like "nofollow" or "external"
+	Rel []string
 }
 
 func (link *Link) String() string {
@@ -20,12 +25,21 @@ func (link *Link) String() string {
 }
 
 func parseHref(linkNode *html.Node) string {
-	for _, attr := range linkNode.Attr {
-		if attr.Key == "href" {
-			return attr.Val
-		}
+	return getAttr(linkNode, "href")
+}
+
+// anchorText returns the text of the link. Links wrapping images without text are named after the image alt text
+func anchorText(linkNode *html.Node) string {
+	if text := textContent(linkNode); text != "" {
+		return text
 	}
-	return ""
+	name := ""
+	walk(linkNode, func(node *html.Node) {
+		if name == "" && node.Data == "img" {
+			name = strings.TrimSpace(getAttr(node, "alt"))
+		}
+	})
+	return name
 }
 
 // LinkParseError is passed when parsing of href on <a> tag fails
@@ -53,13 +67,10 @@ func seekLinkNodes(node *html.Node, outChan chan Link, errChan chan LinkParseErr
 					Href: href,
 				}
 			} else {
-				var name string
-				if child := node.FirstChild; child != nil {
-					name = child.Data
-				}
 				outChan <- Link{
-					Name: name,
+					Name: anchorText(node),
 					URL:  *url,
+					Rel:  strings.Fields(strings.ToLower(getAttr(node, "rel"))),
 				}
 			}
 		}
@@ -72,18 +83,11 @@ func seekLinkNodes(node *html.Node, outChan chan Link, errChan chan LinkParseErr
 
 // FindLinks parses HTML page passed by reader and finds all successfully found links in <a> tags through channel
 func FindLinks(reader io.Reader) (<-chan Link, <-chan LinkParseError, error) {
-	node, err := html.Parse(reader)
+	doc, err := ParseDocument(reader)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	oc := make(chan Link)
-	ec := make(chan LinkParseError)
-	go func() {
-		seekLinkNodes(node, oc, ec)
-		close(oc)
-		close(ec)
-	}()
-
+	oc, ec := doc.Links()
 	return oc, ec, nil
 }
@@ -0,0 +1,123 @@
+package links
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Meta holds the metadata of the page taken from its head, Open Graph tags and JSON-LD structured data
+type Meta struct {
+	Title       string
+	Description string
+	// Lang is the language code of the page like "en" or "pt-BR"
+	Lang string
+	// SiteName is the name of website or publication the page belongs to
+	SiteName string
+	// Published is the publication time of the article, it's zero if the page doesn't tell it
+	Published time.Time
+}
+
+// articleTypes are schema.org types whose JSON-LD data describes the article of the page
+var articleTypes = []string{"NewsArticle", "Article", "BlogPosting", "ReportageNewsArticle"}
+
+// publishedMetaNames lists the names of <meta> tags used to tell the publication time, in the order of preference
+var publishedMetaNames = []string{"article:published_time", "og:article:published_time", "datePublished", "pubdate", "publishdate", "date", "dc.date.issued"}
+
+// parseTime parses the datetime in the formats commonly used by page metadata
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, f := range []string{time.RFC3339Nano, "2006-01-02T15:04:05Z0700", "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02"} {
+		if t, err := time.Parse(f, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Meta collects the metadata of the page. Open Graph and JSON-LD values take precedence over the plain HTML ones
+func (doc *Document) Meta() Meta {
+	var meta Meta
+	var title, headline string
+	metas := make(map[string]string)
+
+	walk(doc.root, func(node *html.Node) {
+		switch node.Data {
+		case "html":
+			meta.Lang = strings.TrimSpace(getAttr(node, "lang"))
+		case "title":
+			if title == "" {
+				title = textContent(node)
+			}
+		case "meta":
+			key := getAttr(node, "property")
+			if key == "" {
+				key = getAttr(node, "name")
+			}
+			if key == "" {
+				key = getAttr(node, "http-equiv")
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			if _, has := metas[key]; key != "" && !has {
+				metas[key] = strings.TrimSpace(getAttr(node, "content"))
+			}
+		}
+	})
+
+	for _, data := range doc.jsonLDScripts() {
+		findJSONLDNodes(data, func(node map[string]interface{}) {
+			isArticle := false
+			for _, t := range articleTypes {
+				if isType(node, t) {
+					isArticle = true
+					break
+				}
+			}
+			if !isArticle {
+				return
+			}
+			if headline == "" {
+				headline = jsonString(node["headline"])
+			}
+			if meta.Published.IsZero() {
+				meta.Published = parseTime(jsonString(node["datePublished"]))
+			}
+			if publisher, ok := node["publisher"].(map[string]interface{}); ok && meta.SiteName == "" {
+				meta.SiteName = jsonString(publisher["name"])
+			}
+			if meta.Lang == "" {
+				meta.Lang = jsonString(node["inLanguage"])
+			}
+		})
+	}
+
+	meta.Title = headline
+	if meta.Title == "" {
+		meta.Title = metas["og:title"]
+	}
+	if meta.Title == "" {
+		meta.Title = title
+	}
+	meta.Description = metas["og:description"]
+	if meta.Description == "" {
+		meta.Description = metas["description"]
+	}
+	if og := metas["og:site_name"]; og != "" {
+		meta.SiteName = og
+	}
+	if meta.Lang == "" {
+		meta.Lang = metas["content-language"]
+	}
+	if meta.Lang == "" {
+		// Open Graph uses locales like en_US
+		meta.Lang = strings.Replace(metas["og:locale"], "_", "-", 1)
+	}
+	for _, name := range publishedMetaNames {
+		if !meta.Published.IsZero() {
+			break
+		}
+		meta.Published = parseTime(metas[strings.ToLower(name)])
+	}
+	return meta
+}
@@ -0,0 +1,270 @@
+package links
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Video is a video embedded into the page. Fields that couldn't be found on the page are left empty
+type Video struct {
+	Title        string
+	Description  string
+	ThumbnailURL url.URL
+	// ContentURL points to the video file itself
+	ContentURL url.URL
+	// PlayerURL points to the embeddable player of the video
+	PlayerURL url.URL
+	Duration  time.Duration
+}
+
+// keys identify the video to merge the data about it found in different places of the page.
+// Scheme and query are ignored since embed codes tend to differ in player parameters
+func (v *Video) keys() []string {
+	keys := make([]string, 0, 2)
+	for _, u := range []url.URL{v.ContentURL, v.PlayerURL} {
+		if u.Host == "" && u.Path == "" {
+			continue
+		}
+		keys = append(keys, u.Host+u.Path)
+	}
+	return keys
+}
+
+// merge fills the empty fields of the video with values from other
+func (v *Video) merge(other Video) {
+	if v.Title == "" {
+		v.Title = other.Title
+	}
+	if v.Description == "" {
+		v.Description = other.Description
+	}
+	if v.ThumbnailURL.String() == "" {
+		v.ThumbnailURL = other.ThumbnailURL
+	}
+	if v.ContentURL.String() == "" {
+		v.ContentURL = other.ContentURL
+	}
+	if v.PlayerURL.String() == "" {
+		v.PlayerURL = other.PlayerURL
+	}
+	if v.Duration == 0 {
+		v.Duration = other.Duration
+	}
+}
+
+// embedPlayer describes a known video hosting whose players are embedded with iframes
+type embedPlayer struct {
+	pattern *regexp.Regexp
+	// thumbnail builds the URL of video thumbnail from its ID, if the hosting allows to do so
+	thumbnail func(id string) string
+}
+
+var embedPlayers = []embedPlayer{
+	{
+		pattern: regexp.MustCompile(`^(?:https?:)?//(?:www\.)?youtube(?:-nocookie)?\.com/embed/([\w-]+)`),
+		thumbnail: func(id string) string {
+			return "https://i.ytimg.com/vi/" + id + "/hqdefault.jpg"
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`^(?:https?:)?//player\.vimeo\.com/video/(\d+)`),
+	},
+	{
+		pattern: regexp.MustCompile(`^(?:https?:)?//(?:www\.)?dailymotion\.com/embed/video/(\w+)`),
+		thumbnail: func(id string) string {
+			return "https://www.dailymotion.com/thumbnail/video/" + id
+		},
+	},
+}
+
+var isoDurationRegexp = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration parses durations like PT1H2M3S used by schema.org, 0 is returned if the string can't be parsed
+func parseISODuration(s string) time.Duration {
+	m := isoDurationRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0
+	}
+	var d time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if m[i+1] == "" {
+			continue
+		}
+		n, _ := strconv.ParseFloat(m[i+1], 64)
+		d += time.Duration(n * float64(unit))
+	}
+	return d
+}
+
+func parseURL(s string) url.URL {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return url.URL{}
+	}
+	return *u
+}
+
+// jsonString returns the string value of JSON-LD property which might be either a string or an array of strings
+func jsonString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []interface{}:
+		if len(t) > 0 {
+			return jsonString(t[0])
+		}
+	case map[string]interface{}:
+		// Some properties are given as objects like ImageObject
+		if u, ok := t["url"]; ok {
+			return jsonString(u)
+		}
+	}
+	return ""
+}
+
+// isType checks if the JSON-LD node has given @type
+func isType(node map[string]interface{}, typeName string) bool {
+	switch t := node["@type"].(type) {
+	case string:
+		return t == typeName
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s == typeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findJSONLDNodes calls fn for every object of JSON-LD data including nested ones
+func findJSONLDNodes(data interface{}, fn func(map[string]interface{})) {
+	switch t := data.(type) {
+	case []interface{}:
+		for _, v := range t {
+			findJSONLDNodes(v, fn)
+		}
+	case map[string]interface{}:
+		fn(t)
+		// Keep the order of nested nodes stable between runs
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			findJSONLDNodes(t[k], fn)
+		}
+	}
+}
+
+// jsonLDScripts returns the decoded content of all JSON-LD scripts of the page. Malformed scripts are skipped
+func (doc *Document) jsonLDScripts() []interface{} {
+	scripts := make([]interface{}, 0)
+	walk(doc.root, func(node *html.Node) {
+		if node.Data != "script" || !strings.EqualFold(strings.TrimSpace(getAttr(node, "type")), "application/ld+json") {
+			return
+		}
+		if node.FirstChild == nil {
+			return
+		}
+		var data interface{}
+		if err := json.Unmarshal([]byte(node.FirstChild.Data), &data); err == nil {
+			scripts = append(scripts, data)
+		}
+	})
+	return scripts
+}
+
+// Videos finds all videos of the page in <video> elements, iframes of known video hostings and JSON-LD VideoObject data.
+// Data about the same video found in several places is merged, each video is returned only once
+func (doc *Document) Videos() []Video {
+	videos := make([]Video, 0)
+	indexes := make(map[string]int)
+	add := func(v Video) {
+		keys := v.keys()
+		if len(keys) == 0 {
+			return
+		}
+		for _, key := range keys {
+			if i, ok := indexes[key]; ok {
+				videos[i].merge(v)
+				for _, k := range videos[i].keys() {
+					indexes[k] = i
+				}
+				return
+			}
+		}
+		for _, key := range keys {
+			indexes[key] = len(videos)
+		}
+		videos = append(videos, v)
+	}
+
+	// Structured data is the most complete source, so it goes first
+	for _, data := range doc.jsonLDScripts() {
+		findJSONLDNodes(data, func(node map[string]interface{}) {
+			if !isType(node, "VideoObject") {
+				return
+			}
+			add(Video{
+				Title:        jsonString(node["name"]),
+				Description:  jsonString(node["description"]),
+				ThumbnailURL: parseURL(jsonString(node["thumbnailUrl"])),
+				ContentURL:   parseURL(jsonString(node["contentUrl"])),
+				PlayerURL:    parseURL(jsonString(node["embedUrl"])),
+				Duration:     parseISODuration(jsonString(node["duration"])),
+			})
+		})
+	}
+
+	walk(doc.root, func(node *html.Node) {
+		switch node.Data {
+		case "video":
+			src := getAttr(node, "src")
+			if src == "" {
+				for c := node.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == html.ElementNode && c.Data == "source" && getAttr(c, "src") != "" {
+						src = getAttr(c, "src")
+						break
+					}
+				}
+			}
+			title := getAttr(node, "title")
+			if title == "" {
+				title = getAttr(node, "aria-label")
+			}
+			add(Video{
+				Title:        strings.TrimSpace(title),
+				ThumbnailURL: parseURL(getAttr(node, "poster")),
+				ContentURL:   parseURL(src),
+			})
+		case "iframe":
+			src := strings.TrimSpace(getAttr(node, "src"))
+			for _, player := range embedPlayers {
+				m := player.pattern.FindStringSubmatch(src)
+				if m == nil {
+					continue
+				}
+				v := Video{
+					Title:     strings.TrimSpace(getAttr(node, "title")),
+					PlayerURL: parseURL(src),
+				}
+				if player.thumbnail != nil {
+					v.ThumbnailURL = parseURL(player.thumbnail(m[1]))
+				}
+				add(v)
+				break
+			}
+		}
+	})
+	return videos
+}
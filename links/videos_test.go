@@ -0,0 +1,87 @@
+package links
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"PT1H2M3S", time.Hour + 2*time.Minute + 3*time.Second},
+		{"PT90S", 90 * time.Second},
+		{"PT1.5S", 1500 * time.Millisecond},
+		{"P1DT1H", 25 * time.Hour},
+		{" PT5M ", 5 * time.Minute},
+		{"P", 0},
+		{"", 0},
+		{"1:30", 0},
+		{"PT1Y", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := parseISODuration(tt.s); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVideos(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []Video
+	}{
+		{"none", `<p>text</p>`, []Video{}},
+		{"video element", `<video src="/intro.mp4" poster="/thumb.jpg" title="Intro"></video>`, []Video{
+			{Title: "Intro", ThumbnailURL: parseURL("/thumb.jpg"), ContentURL: parseURL("/intro.mp4")},
+		}},
+		{"video source", `<video aria-label="Intro"><source src="/intro.webm"><source src="/intro.mp4"></video>`, []Video{
+			{Title: "Intro", ContentURL: parseURL("/intro.webm")},
+		}},
+		{"youtube", `<iframe src="https://www.youtube.com/embed/abc-1?autoplay=1" title="Intro"></iframe>`, []Video{
+			{Title: "Intro", ThumbnailURL: parseURL("https://i.ytimg.com/vi/abc-1/hqdefault.jpg"), PlayerURL: parseURL("https://www.youtube.com/embed/abc-1?autoplay=1")},
+		}},
+		{"unknown iframe", `<iframe src="https://example.com/embed/1"></iframe>`, []Video{}},
+		{"JSON-LD", `<script type="application/ld+json">{
+			"@context": "https://schema.org", "@type": "VideoObject", "name": "Intro", "description": "About us",
+			"thumbnailUrl": ["/thumb.jpg"], "contentUrl": "/intro.mp4", "duration": "PT1M30S"
+		}</script>`, []Video{
+			{Title: "Intro", Description: "About us", ThumbnailURL: parseURL("/thumb.jpg"), ContentURL: parseURL("/intro.mp4"), Duration: 90 * time.Second},
+		}},
+		{"nested JSON-LD", `<script type="application/ld+json">{
+			"@type": "WebPage", "video": {"@type": ["VideoObject"], "name": "Intro", "embedUrl": "https://player.vimeo.com/video/1", "duration": "bad"}
+		}</script>`, []Video{
+			{Title: "Intro", PlayerURL: parseURL("https://player.vimeo.com/video/1")},
+		}},
+		{"malformed JSON-LD", `<script type="application/ld+json">{"@type": "VideoObject",</script>`, []Video{}},
+		// The same video found in JSON-LD and the page is merged, JSON-LD values take precedence
+		{"merged", `<script type="application/ld+json">{"@type": "VideoObject", "name": "Intro", "contentUrl": "/intro.mp4", "duration": "PT10S"}</script>
+			<video src="/intro.mp4" poster="/thumb.jpg" title="Other"></video>`, []Video{
+			{Title: "Intro", ThumbnailURL: parseURL("/thumb.jpg"), ContentURL: parseURL("/intro.mp4"), Duration: 10 * time.Second},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("ParseDocument failed: %v", err)
+			}
+			got := doc.Videos()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got videos %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Title != w.Title || g.Description != w.Description || g.Duration != w.Duration ||
+					g.ThumbnailURL.String() != w.ThumbnailURL.String() || g.ContentURL.String() != w.ContentURL.String() || g.PlayerURL.String() != w.PlayerURL.String() {
+					t.Errorf("video %d is %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
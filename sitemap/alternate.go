@@ -0,0 +1,64 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// XhtmlNamespace is the namespace of XHTML elements used to list the language alternates of the page
+const XhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+// hreflangRegexp matches the language codes allowed in hreflang: ISO 639-1 language optionally followed by script and ISO 3166-1 region
+var hreflangRegexp = regexp.MustCompile(`^(?i:[a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|\d{3}))?|x-default)$`)
+
+// IsValidHreflang checks if the value can be used as hreflang
+func IsValidHreflang(lang string) bool {
+	return hreflangRegexp.MatchString(lang)
+}
+
+// Alternate is an xhtml:link entry referencing the version of the page in another language
+type Alternate struct {
+	XMLName  xml.Name `xml:"xhtml:link"`
+	Rel      string   `xml:"rel,attr"`
+	Hreflang string   `xml:"hreflang,attr"`
+	Href     string   `xml:"href,attr"`
+}
+
+// NewAlternate creates new Alternate struct instance
+func NewAlternate(hreflang, href string) *Alternate {
+	return &Alternate{
+		Rel:      "alternate",
+		Hreflang: hreflang,
+		Href:     href,
+	}
+}
+
+// alternateElement is the form of Alternate used for reading, see imageElement
+type alternateElement struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// checkAlternate returns the messages about all the problems with xhtml:link entry
+func checkAlternate(rel, hreflang, href string) []string {
+	msgs := make([]string, 0)
+	if rel != "alternate" {
+		msgs = append(msgs, fmt.Sprintf("<xhtml:link> rel must be \"alternate\", found %q", rel))
+	}
+	if !IsValidHreflang(hreflang) {
+		msgs = append(msgs, fmt.Sprintf("<xhtml:link> hreflang %q is not a valid language code", hreflang))
+	}
+	return append(msgs, checkURL("xhtml:link", href)...)
+}
+
+// ValidateAlternate checks if the xhtml:link entry conforms to the protocol.
+// loc is the location of the page the alternate belongs to, it's only used for reporting
+func ValidateAlternate(loc string, a Alternate) []Violation {
+	violations := make([]Violation, 0)
+	for _, msg := range checkAlternate(a.Rel, a.Hreflang, a.Href) {
+		violations = append(violations, Violation{Loc: loc, Message: msg})
+	}
+	return violations
+}
@@ -0,0 +1,30 @@
+package sitemap
+
+import "encoding/xml"
+
+// ImageNamespace is the namespace of Google image sitemap extension
+const ImageNamespace = "http://www.google.com/schemas/sitemap-image/1.1"
+
+// Image is an entry of image sitemap extension describing an image found on the page
+type Image struct {
+	XMLName xml.Name `xml:"image:image"`
+	Loc     string   `xml:"image:loc"`
+	Title   string   `xml:"image:title,omitempty"`
+	Caption string   `xml:"image:caption,omitempty"`
+}
+
+// NewImage creates new Image struct instance
+func NewImage(location, title, caption string) *Image {
+	return &Image{
+		Loc:     location,
+		Title:   title,
+		Caption: caption,
+	}
+}
+
+// imageElement is the form of Image used for reading, since encoding/xml matches elements by namespace rather than prefix
+type imageElement struct {
+	Loc     string `xml:"http://www.google.com/schemas/sitemap-image/1.1 loc"`
+	Title   string `xml:"http://www.google.com/schemas/sitemap-image/1.1 title"`
+	Caption string `xml:"http://www.google.com/schemas/sitemap-image/1.1 caption"`
+}
@@ -0,0 +1,83 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// NewsNamespace is the namespace of Google News sitemap extension
+const NewsNamespace = "http://www.google.com/schemas/sitemap-news/0.9"
+
+const (
+	// MaxNewsUrls is the maximum amount of URLs a single news sitemap can hold
+	MaxNewsUrls = 1000
+	// NewsMaxAge is the age of articles after which they should not be listed in news sitemap
+	NewsMaxAge = 48 * time.Hour
+)
+
+// newsLanguageRegexp matches ISO 639 language codes, Chinese is the only language which must have a region
+var newsLanguageRegexp = regexp.MustCompile(`^([a-z]{2,3}|zh-cn|zh-tw)$`)
+
+// NewsPublication identifies the publication the article belongs to
+type NewsPublication struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+// News is an entry of news sitemap extension describing the article on the page
+type News struct {
+	XMLName         xml.Name        `xml:"news:news"`
+	Publication     NewsPublication `xml:"news:publication"`
+	PublicationDate string          `xml:"news:publication_date"`
+	Title           string          `xml:"news:title"`
+}
+
+// NewNews creates new News struct instance
+func NewNews(publicationName, language string, published time.Time, title string) *News {
+	return &News{
+		Publication: NewsPublication{
+			Name:     publicationName,
+			Language: language,
+		},
+		PublicationDate: published.Format(timeFormat),
+		Title:           title,
+	}
+}
+
+// newsClockSkew is how far in the future the publication time may be, since the clocks of servers are not exact
+const newsClockSkew = 5 * time.Minute
+
+// IsRecentNews checks if the article published at given time is fresh enough to be listed in news sitemap.
+// Articles published in the future are rejected, allowing for a few minutes of clock skew
+func IsRecentNews(published, now time.Time) bool {
+	age := now.Sub(published)
+	return !published.IsZero() && age <= NewsMaxAge && age >= -newsClockSkew
+}
+
+// newsElement is the form of News used for reading, see imageElement
+type newsElement struct {
+	Name            string `xml:"http://www.google.com/schemas/sitemap-news/0.9 publication>name"`
+	Language        string `xml:"http://www.google.com/schemas/sitemap-news/0.9 publication>language"`
+	PublicationDate string `xml:"http://www.google.com/schemas/sitemap-news/0.9 publication_date"`
+	Title           string `xml:"http://www.google.com/schemas/sitemap-news/0.9 title"`
+}
+
+// checkNews returns the messages about all the problems with news extension entry
+func checkNews(name, language, publicationDate, title string) []string {
+	msgs := make([]string, 0)
+	if name == "" {
+		msgs = append(msgs, "<news:name> is missing or empty")
+	}
+	if !newsLanguageRegexp.MatchString(language) {
+		msgs = append(msgs, fmt.Sprintf("<news:language> %q must be ISO 639 language code", language))
+	}
+	if !isW3CTime(publicationDate) {
+		msgs = append(msgs, fmt.Sprintf("<news:publication_date> %q is not in W3C Datetime format", publicationDate))
+	}
+	if title == "" {
+		msgs = append(msgs, "<news:title> is missing or empty")
+	}
+	return msgs
+}
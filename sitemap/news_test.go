@@ -0,0 +1,30 @@
+package sitemap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsRecentNews(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name      string
+		published time.Time
+		want      bool
+	}{
+		{"unknown", time.Time{}, false},
+		{"just now", now, true},
+		{"yesterday", now.Add(-24 * time.Hour), true},
+		{"max age", now.Add(-NewsMaxAge), true},
+		{"too old", now.Add(-NewsMaxAge - time.Second), false},
+		{"clock skew", now.Add(time.Minute), true},
+		{"future", now.Add(24 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRecentNews(tt.published, now); got != tt.want {
+				t.Errorf("IsRecentNews(%v) = %t, want %t", tt.published, got, tt.want)
+			}
+		})
+	}
+}
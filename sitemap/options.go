@@ -0,0 +1,161 @@
+package sitemap
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+const (
+	// MaxUrls is the maximum amount of URLs a single sitemap file can hold according to the protocol
+	MaxUrls = 50000
+	// MaxFileSize is the maximum size in bytes of uncompressed sitemap file according to the protocol
+	MaxFileSize = 50 * 1024 * 1024
+	// MaxSitemaps is the maximum amount of sitemaps a single sitemap index can reference according to the protocol
+	MaxSitemaps = 50000
+)
+
+const (
+	defaultFilePrefix = "sitemap"
+	defaultIndexName  = "sitemap-index.xml"
+)
+
+type writeConfig struct {
+	namespace  string
+	baseURL    string
+	filePrefix string
+	indexName  string
+	maxUrls    int
+	maxSize    int
+	// maxSitemaps limits the number of files written by split writer, so they fit into a single index
+	maxSitemaps int
+	gzip        bool
+	lenient     bool
+	plain       bool
+}
+
+func defaultWriteConfig() writeConfig {
+	return writeConfig{
+		namespace:   defaultSitemapNamespace,
+		filePrefix:  defaultFilePrefix,
+		indexName:   defaultIndexName,
+		maxUrls:     MaxUrls,
+		maxSize:     MaxFileSize,
+		maxSitemaps: MaxSitemaps,
+	}
+}
+
+// WriteOption configures how sitemap files are written
+type WriteOption func(*writeConfig)
+
+// WriteOptionBaseURL sets the URL of the directory the sitemap files are served from. Sitemap index references them by this URL
+func WriteOptionBaseURL(baseURL string) WriteOption {
+	return func(wc *writeConfig) {
+		wc.baseURL = baseURL
+	}
+}
+
+// WriteOptionFilePrefix sets the name prefix for split sitemap files, so they are named as <prefix>-1.xml, <prefix>-2.xml, etc.
+// Default value is "sitemap"
+func WriteOptionFilePrefix(prefix string) WriteOption {
+	return func(wc *writeConfig) {
+		wc.filePrefix = prefix
+	}
+}
+
+// WriteOptionIndexName sets the file name of sitemap index
+// Default value is "sitemap-index.xml"
+func WriteOptionIndexName(name string) WriteOption {
+	return func(wc *writeConfig) {
+		wc.indexName = name
+	}
+}
+
+// WriteOptionLimits lowers the amount of URLs and the size in bytes of a single sitemap file.
+// Values that are not positive or exceed the protocol limits (MaxUrls and MaxFileSize) are ignored
+func WriteOptionLimits(maxUrls, maxSize int) WriteOption {
+	return func(wc *writeConfig) {
+		if maxUrls > 0 && maxUrls < MaxUrls {
+			wc.maxUrls = maxUrls
+		}
+		if maxSize > 0 && maxSize < MaxFileSize {
+			wc.maxSize = maxSize
+		}
+	}
+}
+
+// WriteOptionGzip compresses the written sitemaps with gzip. Split sitemap files get .xml.gz extension
+func WriteOptionGzip() WriteOption {
+	return func(wc *writeConfig) {
+		wc.gzip = true
+	}
+}
+
+// WriteOptionPlain makes Writer produce text sitemaps with a single URL per line instead of XML ones
+func WriteOptionPlain() WriteOption {
+	return func(wc *writeConfig) {
+		wc.plain = true
+	}
+}
+
+// WriteOptionLenient turns off the validation of written entries, so documents violating the protocol can be written as well
+func WriteOptionLenient() WriteOption {
+	return func(wc *writeConfig) {
+		wc.lenient = true
+	}
+}
+
+// writeOptionNamespace sets the namespace of urlset element, so the sets read from documents with other namespaces keep it
+func writeOptionNamespace(namespace string) WriteOption {
+	return func(wc *writeConfig) {
+		if namespace != "" {
+			wc.namespace = namespace
+		}
+	}
+}
+
+func newWriteConfig(options []WriteOption) writeConfig {
+	config := defaultWriteConfig()
+	for _, o := range options {
+		o(&config)
+	}
+	return config
+}
+
+// validate returns *ValidationError if the entries violate the protocol and the lenient mode is off
+func (wc *writeConfig) validate(urls []Url) error {
+	if wc.lenient {
+		return nil
+	}
+	if violations := validateUrls(urls); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// compress wraps w into gzip stream if compression is turned on.
+// The returned function must be called after writing to flush the stream, it doesn't close w
+func (wc *writeConfig) compress(w io.Writer) (io.Writer, func() error) {
+	if !wc.gzip {
+		return w, func() error { return nil }
+	}
+	gz := gzip.NewWriter(w)
+	return gz, gz.Close
+}
+
+// extension returns the file extension for sitemap files
+func (wc *writeConfig) extension() string {
+	ext := ".xml"
+	if wc.plain {
+		ext = ".txt"
+	}
+	if wc.gzip {
+		ext += ".gz"
+	}
+	return ext
+}
+
+// locate builds URL of the file with given name
+func (wc *writeConfig) locate(name string) string {
+	return strings.TrimSuffix(wc.baseURL, "/") + "/" + name
+}
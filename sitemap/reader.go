@@ -0,0 +1,204 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// gzipMagic is the header every gzip stream starts with
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// utf8BOM might precede the content of text sitemaps saved by some editors
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// sniffLen is the amount of bytes peeked to guess the sitemap format
+const sniffLen = 512
+
+// openSitemap wraps r into buffered reader and transparently decompresses it if it's gzipped.
+// The returned flag tells if the content looks like XML document
+func openSitemap(r io.Reader) (*bufio.Reader, bool, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, false, err
+		}
+		br = bufio.NewReader(gz)
+	}
+
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, false, err
+	}
+	head = bytes.TrimPrefix(head, utf8BOM)
+	head = bytes.TrimSpace(head)
+
+	return br, len(head) > 0 && head[0] == '<', nil
+}
+
+// seekRoot skips the prolog of XML document and checks that its root element has expected name
+func seekRoot(dec *xml.Decoder, name string) (*xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("Sitemap has no <%s> root element", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			if se.Name.Local != name {
+				return nil, fmt.Errorf("Expected <%s> root element, found <%s>", name, se.Name.Local)
+			}
+			return &se, nil
+		}
+	}
+}
+
+// rootNamespace returns the default namespace declared on the element
+func rootNamespace(se *xml.StartElement) string {
+	for _, attr := range se.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			return attr.Value
+		}
+	}
+	return se.Name.Space
+}
+
+// decodeChildren decodes every direct child of the current element with given name by calling decode on it
+func decodeChildren(dec *xml.Decoder, name string, decode func(*xml.StartElement) error) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 && t.Name.Local == name {
+				if err := decode(&t); err != nil {
+					return err
+				}
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		}
+	}
+}
+
+// ReadUrls reads sitemap from r and passes its entries to fn one by one, so the whole document is never kept in memory.
+// Both XML and plain text sitemaps are supported, gzip-compressed input is detected automatically.
+// Reading stops on the first error returned by fn
+func ReadUrls(r io.Reader, fn func(Url) error) error {
+	return readUrls(r, nil, fn)
+}
+
+// readUrls implements ReadUrls and reports the namespace of urlset element to onRoot if it's set
+func readUrls(r io.Reader, onRoot func(namespace string), fn func(Url) error) error {
+	br, isXML, err := openSitemap(r)
+	if err != nil {
+		return err
+	}
+	if !isXML {
+		return readPlainUrls(br, fn)
+	}
+
+	dec := xml.NewDecoder(br)
+	root, err := seekRoot(dec, "urlset")
+	if err != nil {
+		return err
+	}
+	if ns := rootNamespace(root); ns != "" && onRoot != nil {
+		onRoot(ns)
+	}
+	return decodeChildren(dec, "url", func(se *xml.StartElement) error {
+		var ue urlElement
+		if err := dec.DecodeElement(&ue, se); err != nil {
+			return err
+		}
+		return fn(ue.toUrl())
+	})
+}
+
+// readPlainUrls reads text sitemap where URLs are separated by whitespace
+func readPlainUrls(r io.Reader, fn func(Url) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	first := true
+	for scanner.Scan() {
+		loc := scanner.Text()
+		if first {
+			loc = strings.TrimPrefix(loc, string(utf8BOM))
+			first = false
+		}
+		if loc == "" {
+			continue
+		}
+		if err := fn(Url{Loc: loc}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ParseUrlSet reads the whole sitemap from r into UrlSet.
+// See ReadUrls for the supported formats
+func ParseUrlSet(r io.Reader) (*UrlSet, error) {
+	us := NewUrlSet()
+	err := readUrls(r, func(ns string) {
+		us.Namespace = ns
+	}, func(u Url) error {
+		us.AddUrl(u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+// ParseIndex reads sitemap index from r, gzip-compressed input is detected automatically
+func ParseIndex(r io.Reader) (*Index, error) {
+	br, isXML, err := openSitemap(r)
+	if err != nil {
+		return nil, err
+	}
+	if !isXML {
+		return nil, errors.New("Sitemap index must be an XML document")
+	}
+
+	dec := xml.NewDecoder(br)
+	if _, err := seekRoot(dec, "sitemapindex"); err != nil {
+		return nil, err
+	}
+	index := NewIndex()
+	err = decodeChildren(dec, "sitemap", func(se *xml.StartElement) error {
+		var sm sitemap
+		if err := dec.DecodeElement(&sm, se); err != nil {
+			return err
+		}
+		index.Sitemaps = append(index.Sitemaps, sitemap{
+			Loc:     strings.TrimSpace(sm.Loc),
+			Lastmod: strings.TrimSpace(sm.Lastmod),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
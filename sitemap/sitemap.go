@@ -0,0 +1,213 @@
+// Package sitemap reads, writes and validates sitemaps and sitemap indexes following the protocol at sitemaps.org,
+// along with the image, video, news and hreflang extensions
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Url struct {
+	XMLName    xml.Name    `xml:"url"`
+	Loc        string      `xml:"loc"`
+	Lastmod    string      `xml:"lastmod,omitempty"`
+	Changefreq string      `xml:"changefreq,omitempty"`
+	Priority   float64     `xml:"priority,omitempty"`
+	Images     []Image     `xml:"image:image,omitempty"`
+	Videos     []Video     `xml:"video:video,omitempty"`
+	News       *News       `xml:"news:news,omitempty"`
+	Alternates []Alternate `xml:"xhtml:link,omitempty"`
+}
+
+// extensions maps prefixes of supported sitemap extensions to their namespaces
+var extensions = [][2]string{
+	{"image", ImageNamespace},
+	{"video", VideoNamespace},
+	{"news", NewsNamespace},
+	{"xhtml", XhtmlNamespace},
+}
+
+// urlElement is the form of Url used for reading, see imageElement
+type urlElement struct {
+	Loc        string             `xml:"loc"`
+	Lastmod    string             `xml:"lastmod"`
+	Changefreq string             `xml:"changefreq"`
+	Priority   float64            `xml:"priority"`
+	Images     []imageElement     `xml:"http://www.google.com/schemas/sitemap-image/1.1 image"`
+	Videos     []videoElement     `xml:"http://www.google.com/schemas/sitemap-video/1.1 video"`
+	News       *newsElement       `xml:"http://www.google.com/schemas/sitemap-news/0.9 news"`
+	Alternates []alternateElement `xml:"http://www.w3.org/1999/xhtml link"`
+}
+
+func (ue *urlElement) toUrl() Url {
+	u := Url{
+		Loc:        strings.TrimSpace(ue.Loc),
+		Lastmod:    strings.TrimSpace(ue.Lastmod),
+		Changefreq: strings.TrimSpace(ue.Changefreq),
+		Priority:   ue.Priority,
+	}
+	for _, img := range ue.Images {
+		u.Images = append(u.Images, Image{
+			Loc:     strings.TrimSpace(img.Loc),
+			Title:   img.Title,
+			Caption: img.Caption,
+		})
+	}
+	for _, v := range ue.Videos {
+		duration, _ := strconv.Atoi(strings.TrimSpace(v.Duration))
+		u.Videos = append(u.Videos, Video{
+			ThumbnailLoc: strings.TrimSpace(v.ThumbnailLoc),
+			Title:        v.Title,
+			Description:  v.Description,
+			ContentLoc:   strings.TrimSpace(v.ContentLoc),
+			PlayerLoc:    strings.TrimSpace(v.PlayerLoc),
+			Duration:     duration,
+		})
+	}
+	if n := ue.News; n != nil {
+		u.News = &News{
+			Publication: NewsPublication{
+				Name:     n.Name,
+				Language: strings.TrimSpace(n.Language),
+			},
+			PublicationDate: strings.TrimSpace(n.PublicationDate),
+			Title:           n.Title,
+		}
+	}
+	for _, a := range ue.Alternates {
+		u.Alternates = append(u.Alternates, Alternate{
+			Rel:      a.Rel,
+			Hreflang: a.Hreflang,
+			Href:     strings.TrimSpace(a.Href),
+		})
+	}
+	return u
+}
+
+// timeFormat is W3C Datetime format required by the protocol
+const timeFormat string = "2006-01-02T15:04:05-07:00"
+
+// FormatTime formats the time in W3C Datetime format used for lastmod
+func FormatTime(t time.Time) string {
+	return t.Format(timeFormat)
+}
+
+// NewUrl creates new Url struct instance
+func NewUrl(location, lastmod, changefreq string, priority float64) *Url {
+	if lastmod == "" {
+		lastmod = time.Now().Format(timeFormat)
+	}
+	return &Url{
+		Loc:        location,
+		Lastmod:    lastmod,
+		Changefreq: changefreq,
+		Priority:   priority,
+	}
+}
+
+type UrlSet struct {
+	XMLName   xml.Name `xml:"urlset"`
+	Namespace string   `xml:"xmlns,attr"`
+	Urls      []Url    `xml:"url"`
+}
+
+const defaultSitemapNamespace string = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+func NewUrlSet() *UrlSet {
+	return &UrlSet{
+		Namespace: defaultSitemapNamespace,
+		Urls:      make([]Url, 0),
+	}
+}
+
+func (us *UrlSet) AddUrl(urls ...Url) {
+	us.Urls = append(us.Urls, urls...)
+}
+
+// WriteXml writes the set as XML document into w. Only WriteOptionGzip and WriteOptionLenient are taken into account from the options.
+// Unless the lenient mode is on, the set is validated first and *ValidationError is returned if it violates the protocol
+func (us *UrlSet) WriteXml(w io.Writer, options ...WriteOption) error {
+	config := newWriteConfig(options)
+	if err := config.validate(us.Urls); err != nil {
+		return err
+	}
+
+	// The set is already validated, the rest of options like WriteOptionPlain must not change the document
+	xmlOptions := []WriteOption{writeOptionNamespace(us.Namespace), WriteOptionLenient()}
+	if config.gzip {
+		xmlOptions = append(xmlOptions, WriteOptionGzip())
+	}
+	sw := NewWriter(w, xmlOptions...)
+	for _, u := range us.Urls {
+		if err := sw.Add(u); err != nil {
+			return err
+		}
+	}
+	return sw.Close()
+}
+
+// WritePlain writes the locations of URLs into w as text. Only WriteOptionGzip and WriteOptionLenient are taken into account from the options.
+// Unless the lenient mode is on, the set is validated first and *ValidationError is returned if it violates the protocol
+func (us *UrlSet) WritePlain(w io.Writer, options ...WriteOption) error {
+	config := newWriteConfig(options)
+	if err := config.validate(us.Urls); err != nil {
+		return err
+	}
+	w, flush := config.compress(w)
+
+	// The protocol requires text sitemaps to have one URL per line
+	for _, u := range us.Urls {
+		if _, err := w.Write([]byte(u.Loc + "\n")); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+type sitemap struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+	Lastmod string   `xml:"lastmod,omitempty"`
+}
+
+type Index struct {
+	XMLName   xml.Name  `xml:"sitemapindex"`
+	Namespace string    `xml:"xmlns,attr"`
+	Sitemaps  []sitemap `xml:"sitemap"`
+}
+
+func NewIndex() *Index {
+	return &Index{
+		Namespace: defaultSitemapNamespace,
+		Sitemaps:  make([]sitemap, 0),
+	}
+}
+
+// AddSitemap adds the reference to sitemap file located at given URL.
+// If lastmod is empty, the current time is used
+func (index *Index) AddSitemap(location, lastmod string) {
+	if lastmod == "" {
+		lastmod = time.Now().Format(timeFormat)
+	}
+	sm := sitemap{
+		Loc:     location,
+		Lastmod: lastmod,
+	}
+	index.Sitemaps = append(index.Sitemaps, sm)
+}
+
+func (index *Index) WriteXml(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", strings.Repeat(" ", 4))
+
+	if err := enc.Encode(index); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,437 @@
+package sitemap
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxLocLength is the maximum length of URL in sitemap according to the protocol
+const MaxLocLength = 2048
+
+// MaxImagesPerUrl is the maximum amount of images a single URL entry can have according to image sitemap extension
+const MaxImagesPerUrl = 1000
+
+// w3cTimeFormats lists all datetime formats allowed by W3C Datetime specification
+var w3cTimeFormats = []string{
+	"2006",
+	"2006-01",
+	"2006-01-02",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+}
+
+var changefreqValues = []string{"always", "hourly", "daily", "weekly", "monthly", "yearly", "never"}
+
+// Violation describes a single breach of sitemap protocol
+type Violation struct {
+	// Line is the line of the document where violation was found, it's 0 when the entry is not read from document
+	Line int
+	// Loc is the location of the entry violating the protocol, if it's known
+	Loc     string
+	Message string
+}
+
+func (v Violation) String() string {
+	msg := v.Message
+	if v.Loc != "" {
+		msg = fmt.Sprintf("%s: %s", v.Loc, msg)
+	}
+	if v.Line > 0 {
+		msg = fmt.Sprintf("line %d: %s", v.Line, msg)
+	}
+	return msg
+}
+
+// ValidationError is returned by writers refusing to write a document that violates the protocol
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (err *ValidationError) Error() string {
+	if len(err.Violations) == 1 {
+		return fmt.Sprintf("Sitemap violates the protocol: %s", err.Violations[0])
+	}
+	return fmt.Sprintf("Sitemap violates the protocol in %d places, the first one is %s", len(err.Violations), err.Violations[0])
+}
+
+// isW3CTime checks if the string is a datetime in one of W3C Datetime formats
+func isW3CTime(s string) bool {
+	for _, f := range w3cTimeFormats {
+		if _, err := time.Parse(f, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isEscaped checks that URL has only characters allowed by RFC 3986 and all the rest are percent-encoded
+func isEscaped(loc string) bool {
+	for i := 0; i < len(loc); i++ {
+		c := loc[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(loc) || !isHex(loc[i+1]) || !isHex(loc[i+2]) {
+				return false
+			}
+		case c <= ' ' || c >= 0x7f:
+			return false
+		case strings.IndexByte(`<>"{}|\^`+"`", c) >= 0:
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// checkLoc returns the messages about all the problems with location of sitemap entry
+func checkLoc(loc string) []string {
+	return checkURL("loc", loc)
+}
+
+// checkURL returns the messages about all the problems with URL held by element with given name
+func checkURL(name, loc string) []string {
+	if loc == "" {
+		return []string{fmt.Sprintf("<%s> is missing or empty", name)}
+	}
+	msgs := make([]string, 0)
+	if len(loc) > MaxLocLength {
+		msgs = append(msgs, fmt.Sprintf("<%s> is longer than %d characters", name, MaxLocLength))
+	}
+	if !isEscaped(loc) {
+		msgs = append(msgs, fmt.Sprintf("<%s> has characters that must be percent-encoded", name))
+	}
+	u, err := url.Parse(loc)
+	if err != nil {
+		return append(msgs, fmt.Sprintf("<%s> is not a valid URL: %s", name, err.Error()))
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		msgs = append(msgs, fmt.Sprintf("<%s> must be an absolute http or https URL", name))
+	}
+	return msgs
+}
+
+// checkImages returns the messages about all the problems with image extension entries
+func checkImages(locs []string) []string {
+	msgs := make([]string, 0)
+	if len(locs) > MaxImagesPerUrl {
+		msgs = append(msgs, fmt.Sprintf("URL has %d images while the limit is %d", len(locs), MaxImagesPerUrl))
+	}
+	for _, loc := range locs {
+		msgs = append(msgs, checkURL("image:loc", loc)...)
+	}
+	return msgs
+}
+
+// checkEntry returns the messages about all the problems with fields of <url> element
+func checkEntry(loc, lastmod, changefreq, priority string) []string {
+	msgs := checkLoc(loc)
+	if lastmod != "" && !isW3CTime(lastmod) {
+		msgs = append(msgs, fmt.Sprintf("<lastmod> %q is not in W3C Datetime format", lastmod))
+	}
+	if changefreq != "" {
+		valid := false
+		for _, v := range changefreqValues {
+			if changefreq == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			msgs = append(msgs, fmt.Sprintf("<changefreq> %q must be one of: %s", changefreq, strings.Join(changefreqValues, ", ")))
+		}
+	}
+	if priority != "" {
+		p, err := strconv.ParseFloat(priority, 64)
+		// NaN fails every comparison, so the value must pass the range check rather than fail it
+		if err != nil || !(p >= 0 && p <= 1) {
+			msgs = append(msgs, fmt.Sprintf("<priority> %q must be a number between 0.0 and 1.0", priority))
+		}
+	}
+	return msgs
+}
+
+// ValidateUrl checks if the entry conforms to the protocol
+func ValidateUrl(u Url) []Violation {
+	priority := ""
+	if u.Priority != 0 {
+		priority = strconv.FormatFloat(u.Priority, 'f', -1, 64)
+	}
+	msgs := checkEntry(u.Loc, u.Lastmod, u.Changefreq, priority)
+	if len(u.Images) > 0 {
+		imageLocs := make([]string, len(u.Images))
+		for i, img := range u.Images {
+			imageLocs[i] = img.Loc
+		}
+		msgs = append(msgs, checkImages(imageLocs)...)
+	}
+	for _, v := range u.Videos {
+		msgs = append(msgs, checkVideo(v.ThumbnailLoc, v.Title, v.Description, v.ContentLoc, v.PlayerLoc)...)
+		// Zero duration is not written, so it means the duration is not given
+		if v.Duration != 0 {
+			if msg := checkVideoDuration(v.Duration); msg != "" {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	if n := u.News; n != nil {
+		msgs = append(msgs, checkNews(n.Publication.Name, n.Publication.Language, n.PublicationDate, n.Title)...)
+	}
+	for _, a := range u.Alternates {
+		msgs = append(msgs, checkAlternate(a.Rel, a.Hreflang, a.Href)...)
+	}
+	violations := make([]Violation, 0)
+	for _, msg := range msgs {
+		violations = append(violations, Violation{Loc: u.Loc, Message: msg})
+	}
+	return violations
+}
+
+// validateUrls checks all entries of the set to be written by a single document
+func validateUrls(urls []Url) []Violation {
+	violations := make([]Violation, 0)
+	if len(urls) > MaxUrls {
+		violations = append(violations, Violation{
+			Message: fmt.Sprintf("Sitemap has %d URLs while the limit is %d", len(urls), MaxUrls),
+		})
+	}
+	for _, u := range urls {
+		violations = append(violations, ValidateUrl(u)...)
+	}
+	return violations
+}
+
+// lineCounter tracks the newlines passed through it to find the line of given offset in the stream
+type lineCounter struct {
+	r    io.Reader
+	read int64
+	// offsets of newlines which are not passed by lineAt queries yet
+	pending []int64
+	line    int
+}
+
+func newLineCounter(r io.Reader) *lineCounter {
+	return &lineCounter{r: r, line: 1}
+}
+
+func (lc *lineCounter) Read(p []byte) (int, error) {
+	n, err := lc.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == '\n' {
+			lc.pending = append(lc.pending, lc.read+int64(i))
+		}
+	}
+	lc.read += int64(n)
+	return n, err
+}
+
+// lineAt returns the line number of given offset. The offsets must not decrease between calls
+func (lc *lineCounter) lineAt(offset int64) int {
+	for len(lc.pending) > 0 && lc.pending[0] < offset {
+		lc.line++
+		lc.pending = lc.pending[1:]
+	}
+	return lc.line
+}
+
+type rawUrl struct {
+	Loc        string             `xml:"loc"`
+	Lastmod    string             `xml:"lastmod"`
+	Changefreq string             `xml:"changefreq"`
+	Priority   string             `xml:"priority"`
+	Images     []imageElement     `xml:"http://www.google.com/schemas/sitemap-image/1.1 image"`
+	Videos     []videoElement     `xml:"http://www.google.com/schemas/sitemap-video/1.1 video"`
+	News       *newsElement       `xml:"http://www.google.com/schemas/sitemap-news/0.9 news"`
+	Alternates []alternateElement `xml:"http://www.w3.org/1999/xhtml link"`
+}
+
+// Validate reads sitemap or sitemap index from r and reports every violation of the protocol found in it.
+// XML, gzip-compressed and plain text documents are supported.
+// The returned error is only set when r can't be read, malformed XML is reported as violation
+func Validate(r io.Reader) ([]Violation, error) {
+	br, isXML, err := openSitemap(r)
+	if err != nil {
+		return nil, err
+	}
+	lc := newLineCounter(br)
+
+	var violations []Violation
+	var count int
+	if isXML {
+		violations, count, err = validateXml(lc)
+	} else {
+		violations, count, err = validatePlain(lc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if count > MaxUrls {
+		violations = append(violations, Violation{
+			Message: fmt.Sprintf("Sitemap has %d entries while the limit is %d", count, MaxUrls),
+		})
+	}
+	if lc.read > MaxFileSize {
+		violations = append(violations, Violation{
+			Message: fmt.Sprintf("Uncompressed sitemap is %d bytes long while the limit is %d", lc.read, MaxFileSize),
+		})
+	}
+	return violations, nil
+}
+
+func validateXml(lc *lineCounter) ([]Violation, int, error) {
+	violations := make([]Violation, 0)
+	count := 0
+	dec := xml.NewDecoder(lc)
+
+	report := func(line int, loc string, msgs ...string) {
+		for _, msg := range msgs {
+			violations = append(violations, Violation{Line: line, Loc: loc, Message: msg})
+		}
+	}
+	// syntax errors make the rest of the document unreadable, so they are reported as the last violation
+	handleErr := func(err error) ([]Violation, int, error) {
+		if se, ok := err.(*xml.SyntaxError); ok {
+			report(se.Line, "", fmt.Sprintf("Malformed XML: %s", se.Msg))
+			return violations, count, nil
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			report(lc.lineAt(dec.InputOffset()), "", "Unexpected end of document")
+			return violations, count, nil
+		}
+		return nil, 0, err
+	}
+
+	var root *xml.StartElement
+	for root == nil {
+		tok, err := dec.Token()
+		if err != nil {
+			return handleErr(err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			root = &se
+		}
+	}
+	rootLine := lc.lineAt(dec.InputOffset())
+
+	var entryName string
+	switch root.Name.Local {
+	case "urlset":
+		entryName = "url"
+	case "sitemapindex":
+		entryName = "sitemap"
+	default:
+		report(rootLine, "", fmt.Sprintf("Root element must be <urlset> or <sitemapindex>, found <%s>", root.Name.Local))
+		return violations, count, nil
+	}
+	if root.Name.Space != defaultSitemapNamespace {
+		report(rootLine, "", fmt.Sprintf("<%s> namespace must be %q, found %q", root.Name.Local, defaultSitemapNamespace, root.Name.Space))
+	}
+
+	err := decodeChildren(dec, entryName, func(se *xml.StartElement) error {
+		count++
+		line := lc.lineAt(dec.InputOffset())
+		var raw rawUrl
+		if err := dec.DecodeElement(&raw, se); err != nil {
+			return err
+		}
+		loc := strings.TrimSpace(raw.Loc)
+		if entryName == "sitemap" {
+			msgs := checkLoc(loc)
+			if lastmod := strings.TrimSpace(raw.Lastmod); lastmod != "" && !isW3CTime(lastmod) {
+				msgs = append(msgs, fmt.Sprintf("<lastmod> %q is not in W3C Datetime format", lastmod))
+			}
+			report(line, loc, msgs...)
+			return nil
+		}
+		report(line, loc, checkEntry(
+			loc,
+			strings.TrimSpace(raw.Lastmod),
+			strings.TrimSpace(raw.Changefreq),
+			strings.TrimSpace(raw.Priority),
+		)...)
+		if len(raw.Images) > 0 {
+			imageLocs := make([]string, len(raw.Images))
+			for i, img := range raw.Images {
+				imageLocs[i] = strings.TrimSpace(img.Loc)
+			}
+			report(line, loc, checkImages(imageLocs)...)
+		}
+		for _, v := range raw.Videos {
+			if d := strings.TrimSpace(v.Duration); d != "" {
+				if duration, err := strconv.Atoi(d); err != nil {
+					report(line, loc, fmt.Sprintf("<video:duration> %q must be a number of seconds", d))
+				} else if msg := checkVideoDuration(duration); msg != "" {
+					report(line, loc, msg)
+				}
+			}
+			report(line, loc, checkVideo(
+				strings.TrimSpace(v.ThumbnailLoc),
+				strings.TrimSpace(v.Title),
+				strings.TrimSpace(v.Description),
+				strings.TrimSpace(v.ContentLoc),
+				strings.TrimSpace(v.PlayerLoc),
+			)...)
+		}
+		if n := raw.News; n != nil {
+			report(line, loc, checkNews(
+				strings.TrimSpace(n.Name),
+				strings.TrimSpace(n.Language),
+				strings.TrimSpace(n.PublicationDate),
+				strings.TrimSpace(n.Title),
+			)...)
+		}
+		for _, a := range raw.Alternates {
+			report(line, loc, checkAlternate(a.Rel, a.Hreflang, strings.TrimSpace(a.Href))...)
+		}
+		return nil
+	})
+	if err != nil {
+		return handleErr(err)
+	}
+	return violations, count, nil
+}
+
+func validatePlain(lc *lineCounter) ([]Violation, int, error) {
+	violations := make([]Violation, 0)
+	count := 0
+	scanner := bufio.NewScanner(lc)
+	// Lines are only limited by the size of the whole sitemap, the long ones are reported by checkLoc
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), MaxFileSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, string(utf8BOM))
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		count++
+		if strings.ContainsAny(text, " \t") {
+			violations = append(violations, Violation{Line: line, Message: "Text sitemap must have a single URL per line"})
+			continue
+		}
+		for _, msg := range checkLoc(text) {
+			violations = append(violations, Violation{Line: line, Loc: text, Message: msg})
+		}
+	}
+	if err := scanner.Err(); err == bufio.ErrTooLong {
+		// The document is over the size limit anyway, which is reported by Validate
+		violations = append(violations, Violation{Line: line + 1, Message: fmt.Sprintf("Line is longer than %d bytes", MaxFileSize)})
+	} else if err != nil {
+		return nil, 0, err
+	}
+	return violations, count, nil
+}
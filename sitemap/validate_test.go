@@ -0,0 +1,98 @@
+package sitemap
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateUrl(t *testing.T) {
+	video := func(duration time.Duration) Video {
+		return *NewVideo("https://example.com/thumb.jpg", "Intro", "About us", "https://example.com/intro.mp4", "", duration)
+	}
+	tests := []struct {
+		name           string
+		url            Url
+		wantViolations int
+	}{
+		{"valid", *NewUrl("https://example.com/", "2020-01-02T03:04:05Z", "weekly", 0.8), 0},
+		{"relative location", *NewUrl("/about", "", "", 0), 1},
+		{"bad lastmod", *NewUrl("https://example.com/", "yesterday", "", 0), 1},
+		{"bad changefreq", *NewUrl("https://example.com/", "", "sometimes", 0), 1},
+		{"priority out of range", *NewUrl("https://example.com/", "", "", 1.5), 1},
+		{"priority not a number", *NewUrl("https://example.com/", "", "", math.NaN()), 1},
+		{"relative image", Url{Loc: "https://example.com/", Images: []Image{*NewImage("logo.png", "", "")}}, 1},
+		{"video without duration", Url{Loc: "https://example.com/", Videos: []Video{video(0)}}, 0},
+		{"video with duration", Url{Loc: "https://example.com/", Videos: []Video{video(time.Minute)}}, 0},
+		{"video too long", Url{Loc: "https://example.com/", Videos: []Video{video(MaxVideoDuration + time.Second)}}, 1},
+		{"video without location", Url{Loc: "https://example.com/", Videos: []Video{*NewVideo("https://example.com/thumb.jpg", "Intro", "About us", "", "", 0)}}, 1},
+		{"news", Url{Loc: "https://example.com/", News: NewNews("Example", "en", time.Now(), "Breaking")}, 0},
+		{"news without publication", Url{Loc: "https://example.com/", News: NewNews("", "en", time.Now(), "Breaking")}, 1},
+		{"alternate", Url{Loc: "https://example.com/", Alternates: []Alternate{*NewAlternate("de-AT", "https://example.com/at/")}}, 0},
+		{"bad hreflang", Url{Loc: "https://example.com/", Alternates: []Alternate{*NewAlternate("german", "https://example.com/de/")}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := ValidateUrl(tt.url)
+			if len(violations) != tt.wantViolations {
+				t.Errorf("got %d violations %v, want %d", len(violations), violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	urlset := func(entries string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:video="http://www.google.com/schemas/sitemap-video/1.1">` + entries + `
+</urlset>`
+	}
+	videoEntry := func(duration string) string {
+		return urlset(`
+<url>
+  <loc>https://example.com/</loc>
+  <video:video>
+    <video:thumbnail_loc>https://example.com/thumb.jpg</video:thumbnail_loc>
+    <video:title>Intro</video:title>
+    <video:description>About us</video:description>
+    <video:content_loc>https://example.com/intro.mp4</video:content_loc>
+    <video:duration>` + duration + `</video:duration>
+  </video:video>
+</url>`)
+	}
+	tests := []struct {
+		name string
+		doc  string
+		// wantLines are the lines of the expected violations, 0 for the ones about the whole document
+		wantLines []int
+	}{
+		{"valid", urlset("\n<url><loc>https://example.com/</loc></url>"), nil},
+		{"relative location", urlset("\n<url><loc>https://example.com/</loc></url>\n<url><loc>/about</loc></url>"), []int{4}},
+		{"priority not a number", urlset("\n<url><loc>https://example.com/</loc><priority>NaN</priority></url>"), []int{3}},
+		{"malformed", urlset("\n<url><loc>https://example.com/</loc>"), []int{4}},
+		{"video duration", videoEntry("600"), nil},
+		{"zero video duration", videoEntry("0"), []int{3}},
+		{"video duration too long", videoEntry("28801"), []int{3}},
+		{"video duration not a number", videoEntry("ten"), []int{3}},
+		{"plain", "https://example.com/\nhttps://example.com/about\n", nil},
+		{"plain relative location", "https://example.com/\n/about\n", []int{2}},
+		{"plain long line", "https://example.com/\nhttps://example.com/" + strings.Repeat("a", 100*1024) + "\n/about\n", []int{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := Validate(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+			if len(violations) != len(tt.wantLines) {
+				t.Fatalf("got violations %v, want them on lines %v", violations, tt.wantLines)
+			}
+			for i, v := range violations {
+				if v.Line != tt.wantLines[i] {
+					t.Errorf("violation %q is on line %d, want %d", v, v.Line, tt.wantLines[i])
+				}
+			}
+		})
+	}
+}
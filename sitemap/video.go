@@ -0,0 +1,100 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// VideoNamespace is the namespace of Google video sitemap extension
+const VideoNamespace = "http://www.google.com/schemas/sitemap-video/1.1"
+
+const (
+	// MaxVideoDescriptionLength is the maximum length of video description according to video sitemap extension
+	MaxVideoDescriptionLength = 2048
+	// MaxVideoDuration is the maximum duration of video according to video sitemap extension
+	MaxVideoDuration = 8 * time.Hour
+)
+
+// Video is an entry of video sitemap extension describing a video embedded into the page
+type Video struct {
+	XMLName      xml.Name `xml:"video:video"`
+	ThumbnailLoc string   `xml:"video:thumbnail_loc"`
+	Title        string   `xml:"video:title"`
+	Description  string   `xml:"video:description"`
+	ContentLoc   string   `xml:"video:content_loc,omitempty"`
+	PlayerLoc    string   `xml:"video:player_loc,omitempty"`
+	// Duration of the video in seconds
+	Duration int `xml:"video:duration,omitempty"`
+}
+
+// NewVideo creates new Video struct instance. Either contentLoc or playerLoc is required by the protocol
+func NewVideo(thumbnailLoc, title, description, contentLoc, playerLoc string, duration time.Duration) *Video {
+	return &Video{
+		ThumbnailLoc: thumbnailLoc,
+		Title:        title,
+		Description:  description,
+		ContentLoc:   contentLoc,
+		PlayerLoc:    playerLoc,
+		Duration:     int(duration.Seconds()),
+	}
+}
+
+// videoElement is the form of Video used for reading, see imageElement
+type videoElement struct {
+	ThumbnailLoc string `xml:"http://www.google.com/schemas/sitemap-video/1.1 thumbnail_loc"`
+	Title        string `xml:"http://www.google.com/schemas/sitemap-video/1.1 title"`
+	Description  string `xml:"http://www.google.com/schemas/sitemap-video/1.1 description"`
+	ContentLoc   string `xml:"http://www.google.com/schemas/sitemap-video/1.1 content_loc"`
+	PlayerLoc    string `xml:"http://www.google.com/schemas/sitemap-video/1.1 player_loc"`
+	Duration     string `xml:"http://www.google.com/schemas/sitemap-video/1.1 duration"`
+}
+
+// checkVideo returns the messages about all the problems with video extension entry except its duration, see checkVideoDuration
+func checkVideo(thumbnailLoc, title, description, contentLoc, playerLoc string) []string {
+	msgs := checkURL("video:thumbnail_loc", thumbnailLoc)
+	if title == "" {
+		msgs = append(msgs, "<video:title> is missing or empty")
+	}
+	if description == "" {
+		msgs = append(msgs, "<video:description> is missing or empty")
+	}
+	if len([]rune(description)) > MaxVideoDescriptionLength {
+		msgs = append(msgs, fmt.Sprintf("<video:description> is longer than %d characters", MaxVideoDescriptionLength))
+	}
+	if contentLoc == "" && playerLoc == "" {
+		msgs = append(msgs, "Either <video:content_loc> or <video:player_loc> is required")
+	}
+	if contentLoc != "" {
+		msgs = append(msgs, checkURL("video:content_loc", contentLoc)...)
+	}
+	if playerLoc != "" {
+		msgs = append(msgs, checkURL("video:player_loc", playerLoc)...)
+	}
+	return msgs
+}
+
+// checkVideoDuration returns the message about the problem with the duration of video given in seconds, or empty string if it's fine
+func checkVideoDuration(seconds int) string {
+	if seconds < 1 || seconds > int(MaxVideoDuration.Seconds()) {
+		return fmt.Sprintf("<video:duration> must be between 1 and %d seconds", int(MaxVideoDuration.Seconds()))
+	}
+	return ""
+}
+
+// ValidateVideo checks if the video entry has all the required fields and conforms to the protocol.
+// loc is the location of the page the video belongs to, it's only used for reporting
+func ValidateVideo(loc string, v Video) []Violation {
+	violations := make([]Violation, 0)
+	msgs := checkVideo(v.ThumbnailLoc, v.Title, v.Description, v.ContentLoc, v.PlayerLoc)
+	// Zero duration is not written, so it means the duration is not given
+	if v.Duration != 0 {
+		if msg := checkVideoDuration(v.Duration); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	for _, msg := range msgs {
+		violations = append(violations, Violation{Loc: loc, Message: msg})
+	}
+	return violations
+}
@@ -0,0 +1,257 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var indent = strings.Repeat(" ", 4)
+
+// ErrLimitExceeded is returned by Writer when the entry doesn't fit into the sitemap and there's no way to start a new file,
+// either because the writer produces a single document or because the sitemap index already references MaxSitemaps files
+var ErrLimitExceeded = errors.New("Sitemap limit of URLs count or file size is exceeded")
+
+// Writer writes sitemap entries one by one as they come, so the memory consumption doesn't depend on the amount of URLs.
+// Writer must be closed after adding the last entry to finish the document
+type Writer struct {
+	config writeConfig
+	// openPart creates the destination for the next sitemap file and returns its name
+	openPart func(num int) (io.WriteCloser, string, error)
+	// index is only kept by writers producing several files
+	index     *Index
+	indexPath string
+
+	part    io.WriteCloser
+	out     io.Writer
+	flush   func() error
+	partNum int
+	count   int
+	size    int
+	total   int
+	header  []byte
+	footer  []byte
+}
+
+func newWriter(config writeConfig, openPart func(int) (io.WriteCloser, string, error)) *Writer {
+	w := &Writer{
+		config:   config,
+		openPart: openPart,
+	}
+	if !config.plain {
+		// Extension namespaces are declared upfront since it's unknown which of them the entries will use
+		header := xml.Header + fmt.Sprintf(`<urlset xmlns="%s"`, config.namespace)
+		for _, ext := range extensions {
+			header += fmt.Sprintf(` xmlns:%s="%s"`, ext[0], ext[1])
+		}
+		w.header = []byte(header + ">")
+		w.footer = []byte("\n</urlset>\n")
+	}
+	return w
+}
+
+// nopCloser keeps the writer passed to NewWriter open, as it's owned by the caller
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// NewWriter makes Writer that writes a single sitemap document into w.
+// Unless the lenient mode is on, adding more entries than the protocol allows fails with ErrLimitExceeded
+func NewWriter(w io.Writer, options ...WriteOption) *Writer {
+	config := newWriteConfig(options)
+	return newWriter(config, func(num int) (io.WriteCloser, string, error) {
+		if num > 1 {
+			return nil, "", ErrLimitExceeded
+		}
+		return nopCloser{w}, "", nil
+	})
+}
+
+// NewSplitWriter makes Writer that writes into directory dir starting a new file each time the current one hits the limits.
+// The files are named as <prefix>-1.xml, <prefix>-2.xml, etc. (or <prefix>-1.xml.gz, etc. with WriteOptionGzip) and
+// referenced by sitemap index file, which is written in the same directory on Close.
+// WriteOptionBaseURL is required since sitemap index must refer to the files by absolute URLs.
+// The index can't reference more than MaxSitemaps files, adding the entry that needs one more file fails with ErrLimitExceeded
+func NewSplitWriter(dir string, options ...WriteOption) (*Writer, error) {
+	config := newWriteConfig(options)
+	if config.baseURL == "" {
+		return nil, errors.New("Base URL is required to reference sitemap files from the index")
+	}
+	w := newWriter(config, func(num int) (io.WriteCloser, string, error) {
+		if num > config.maxSitemaps {
+			return nil, "", ErrLimitExceeded
+		}
+		name := fmt.Sprintf("%s-%d%s", config.filePrefix, num, config.extension())
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return nil, "", err
+		}
+		return f, name, nil
+	})
+	w.index = NewIndex()
+	w.indexPath = filepath.Join(dir, config.indexName)
+	return w, nil
+}
+
+// Add writes the entry into sitemap.
+// Unless the lenient mode is on, the entry is validated first and *ValidationError is returned without writing it if it violates the protocol
+func (w *Writer) Add(u Url) error {
+	if !w.config.lenient {
+		if violations := ValidateUrl(u); len(violations) > 0 {
+			return &ValidationError{Violations: violations}
+		}
+	}
+
+	entry, err := w.encode(u)
+	if err != nil {
+		return err
+	}
+
+	full := w.count >= w.config.maxUrls || w.size+len(entry)+len(w.footer) > w.config.maxSize
+	if w.part != nil && full && (w.index != nil || !w.config.lenient) {
+		if err := w.closePart(); err != nil {
+			return err
+		}
+	}
+	if w.part == nil {
+		if len(w.header)+len(entry)+len(w.footer) > w.config.maxSize && !w.config.lenient {
+			return fmt.Errorf("Sitemap entry for %s exceeds the file size limit", u.Loc)
+		}
+		if err := w.nextPart(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.out.Write(entry); err != nil {
+		return err
+	}
+	w.count++
+	w.total++
+	w.size += len(entry)
+	return nil
+}
+
+// Count returns the amount of entries written so far
+func (w *Writer) Count() int {
+	return w.total
+}
+
+// Index returns the sitemap index referencing all files written so far. It's nil for writers producing a single document
+func (w *Writer) Index() *Index {
+	return w.index
+}
+
+// Close finishes the current sitemap file. Writers producing several files also write sitemap index on close
+func (w *Writer) Close() error {
+	// Even an empty sitemap must be a well-formed document
+	if w.part == nil && w.partNum == 0 {
+		if err := w.nextPart(); err != nil {
+			return err
+		}
+	}
+	if w.part != nil {
+		if err := w.closePart(); err != nil {
+			return err
+		}
+	}
+	if w.index == nil {
+		return nil
+	}
+
+	f, err := os.Create(w.indexPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return w.index.WriteXml(f)
+}
+
+func (w *Writer) encode(u Url) ([]byte, error) {
+	if w.config.plain {
+		return []byte(u.Loc + "\n"), nil
+	}
+	entry, err := xml.MarshalIndent(u, indent, indent)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("\n"), entry...), nil
+}
+
+func (w *Writer) nextPart() error {
+	w.partNum++
+	part, name, err := w.openPart(w.partNum)
+	if err != nil {
+		return err
+	}
+	out, flush := w.config.compress(part)
+	if _, err := out.Write(w.header); err != nil {
+		part.Close()
+		return err
+	}
+	w.part = part
+	w.out = out
+	w.flush = flush
+	w.count = 0
+	w.size = len(w.header)
+	if w.index != nil {
+		w.index.AddSitemap(w.config.locate(name), time.Now().Format(timeFormat))
+	}
+	return nil
+}
+
+func (w *Writer) closePart() error {
+	part := w.part
+	w.part = nil
+	if _, err := w.out.Write(w.footer); err != nil {
+		part.Close()
+		return err
+	}
+	if err := w.flush(); err != nil {
+		part.Close()
+		return err
+	}
+	return part.Close()
+}
+
+// WriteSplit writes the URLs into directory dir splitting them into as many sitemap files as needed to stay within the protocol limits.
+// See NewSplitWriter for the details about the written files.
+// Unless the lenient mode is on, the entries are validated first and *ValidationError is returned if any of them violates the protocol
+func (us *UrlSet) WriteSplit(dir string, options ...WriteOption) (*Index, error) {
+	options = append([]WriteOption{writeOptionNamespace(us.Namespace)}, options...)
+	w, err := NewSplitWriter(dir, options...)
+	if err != nil {
+		return nil, err
+	}
+	// Validate everything before writing to avoid leaving incomplete set of files
+	if !w.config.lenient {
+		violations := make([]Violation, 0)
+		for _, u := range us.Urls {
+			violations = append(violations, ValidateUrl(u)...)
+		}
+		if len(violations) > 0 {
+			return nil, &ValidationError{Violations: violations}
+		}
+	}
+	for _, u := range us.Urls {
+		if err := w.Add(u); err != nil {
+			if w.part != nil {
+				w.part.Close()
+			}
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return w.Index(), nil
+}
@@ -0,0 +1,253 @@
+package sitemap
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testUrls() []Url {
+	page := NewUrl("https://example.com/", "2020-01-02", "daily", 0.5)
+	page.Images = []Image{*NewImage("https://example.com/logo.png", "Logo", "")}
+	page.Videos = []Video{*NewVideo("https://example.com/thumb.jpg", "Intro", "About us", "https://example.com/intro.mp4", "", 90*time.Second)}
+	page.Alternates = []Alternate{*NewAlternate("de", "https://example.com/de/")}
+	article := NewUrl("https://example.com/news/1", "", "", 0)
+	article.News = NewNews("Example", "en", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "Breaking")
+	return []Url{*page, *article, *NewUrl("https://example.com/about", "", "", 0)}
+}
+
+func writeUrls(t *testing.T, urls []Url, options ...WriteOption) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, options...)
+	for _, u := range urls {
+		if err := w.Add(u); err != nil {
+			t.Fatalf("Add(%s) failed: %v", u.Loc, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []WriteOption
+	}{
+		{"xml", nil},
+		{"gzip", []WriteOption{WriteOptionGzip()}},
+		{"plain", []WriteOption{WriteOptionPlain()}},
+		{"plain gzip", []WriteOption{WriteOptionPlain(), WriteOptionGzip()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := testUrls()
+			written := writeUrls(t, urls, tt.options...)
+
+			violations, err := Validate(bytes.NewReader(written))
+			if err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+			if len(violations) > 0 {
+				t.Errorf("written sitemap has violations: %v", violations)
+			}
+
+			read := make([]Url, 0)
+			if err := ReadUrls(bytes.NewReader(written), func(u Url) error {
+				read = append(read, u)
+				return nil
+			}); err != nil {
+				t.Fatalf("ReadUrls failed: %v", err)
+			}
+			if len(read) != len(urls) {
+				t.Fatalf("read %d entries, want %d", len(read), len(urls))
+			}
+			for i := range urls {
+				if read[i].Loc != urls[i].Loc {
+					t.Errorf("entry %d has location %q, want %q", i, read[i].Loc, urls[i].Loc)
+				}
+			}
+
+			// Writing the parsed entries again must produce the same document
+			if again := writeUrls(t, read, tt.options...); !bytes.Equal(again, written) {
+				t.Errorf("sitemap changed after round trip:\n%s\nwant:\n%s", again, written)
+			}
+		})
+	}
+}
+
+func TestParseUrlSet(t *testing.T) {
+	written := writeUrls(t, testUrls())
+	us, err := ParseUrlSet(bytes.NewReader(written))
+	if err != nil {
+		t.Fatalf("ParseUrlSet failed: %v", err)
+	}
+	if len(us.Urls) != 3 {
+		t.Fatalf("parsed %d entries, want 3", len(us.Urls))
+	}
+	page, article := us.Urls[0], us.Urls[1]
+	if page.Lastmod != "2020-01-02" || page.Changefreq != "daily" || page.Priority != 0.5 {
+		t.Errorf("page fields are %q, %q, %v", page.Lastmod, page.Changefreq, page.Priority)
+	}
+	if len(page.Images) != 1 || page.Images[0].Title != "Logo" {
+		t.Errorf("page images are %+v", page.Images)
+	}
+	if len(page.Videos) != 1 || page.Videos[0].Duration != 90 {
+		t.Errorf("page videos are %+v", page.Videos)
+	}
+	if len(page.Alternates) != 1 || page.Alternates[0].Hreflang != "de" {
+		t.Errorf("page alternates are %+v", page.Alternates)
+	}
+	if article.News == nil || article.News.Title != "Breaking" || article.News.Publication.Language != "en" {
+		t.Errorf("article news is %+v", article.News)
+	}
+}
+
+func TestWriterValidation(t *testing.T) {
+	invalid := *NewUrl("/relative", "", "", 0)
+	tests := []struct {
+		name    string
+		options []WriteOption
+		wantErr bool
+	}{
+		{"strict", nil, true},
+		{"lenient", []WriteOption{WriteOptionLenient()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWriter(&bytes.Buffer{}, tt.options...)
+			err := w.Add(invalid)
+			if _, ok := err.(*ValidationError); ok != tt.wantErr {
+				t.Errorf("Add returned %v, want validation error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriterLimit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, WriteOptionLimits(2, 0))
+	for i, u := range testUrls() {
+		err := w.Add(u)
+		if i < 2 && err != nil {
+			t.Fatalf("Add(%s) failed: %v", u.Loc, err)
+		}
+		if i == 2 && err != ErrLimitExceeded {
+			t.Errorf("Add beyond the limit returned %v, want ErrLimitExceeded", err)
+		}
+	}
+	// The entries added before the limit are still written
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	us, err := ParseUrlSet(buf)
+	if err != nil {
+		t.Fatalf("ParseUrlSet failed: %v", err)
+	}
+	if len(us.Urls) != 2 || w.Count() != 2 {
+		t.Errorf("written %d entries and counted %d, want 2", len(us.Urls), w.Count())
+	}
+}
+
+func TestSplitWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxUrls   int
+		wantFiles int
+		wantErr   error
+	}{
+		{"single file", 3, 1, nil},
+		{"split", 2, 2, nil},
+		{"too many files", 1, 2, ErrLimitExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "sitemap")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			w, err := NewSplitWriter(dir,
+				WriteOptionBaseURL("https://example.com/maps/"),
+				WriteOptionLimits(tt.maxUrls, 0),
+				func(wc *writeConfig) {
+					wc.maxSitemaps = 2
+				},
+			)
+			if err != nil {
+				t.Fatalf("NewSplitWriter failed: %v", err)
+			}
+			var addErr error
+			for _, u := range testUrls() {
+				if addErr = w.Add(u); addErr != nil {
+					break
+				}
+			}
+			if addErr != tt.wantErr {
+				t.Errorf("Add returned %v, want %v", addErr, tt.wantErr)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			f, err := os.Open(filepath.Join(dir, defaultIndexName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			index, err := ParseIndex(f)
+			if err != nil {
+				t.Fatalf("ParseIndex failed: %v", err)
+			}
+			if len(index.Sitemaps) != tt.wantFiles {
+				t.Errorf("index references %d files, want %d", len(index.Sitemaps), tt.wantFiles)
+			}
+			if loc := index.Sitemaps[0].Loc; loc != "https://example.com/maps/sitemap-1.xml" {
+				t.Errorf("first file is referenced as %q", loc)
+			}
+		})
+	}
+}
+
+func TestUrlSetWriteXml(t *testing.T) {
+	us := NewUrlSet()
+	us.AddUrl(testUrls()...)
+	tests := []struct {
+		name     string
+		options  []WriteOption
+		wantGzip bool
+	}{
+		{"default", nil, false},
+		{"gzip", []WriteOption{WriteOptionGzip()}, true},
+		// WriteXml always writes XML documents
+		{"plain", []WriteOption{WriteOptionPlain()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := us.WriteXml(buf, tt.options...); err != nil {
+				t.Fatalf("WriteXml failed: %v", err)
+			}
+			written := buf.Bytes()
+			if isGzip := bytes.HasPrefix(written, []byte{0x1f, 0x8b}); isGzip != tt.wantGzip {
+				t.Errorf("document is compressed: %t, want %t", isGzip, tt.wantGzip)
+			}
+			if !tt.wantGzip && !bytes.HasPrefix(written, []byte("<?xml")) {
+				t.Errorf("document is not XML:\n%s", written)
+			}
+			read, err := ParseUrlSet(bytes.NewReader(written))
+			if err != nil {
+				t.Fatalf("ParseUrlSet failed: %v", err)
+			}
+			if len(read.Urls) != len(us.Urls) {
+				t.Errorf("read %d entries, want %d", len(read.Urls), len(us.Urls))
+			}
+		})
+	}
+}